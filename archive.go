@@ -0,0 +1,304 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// progressReader counts what is read through it and reports the running
+// total, on top of the offset a resumed download starts from.
+type progressReader struct {
+	r io.Reader
+	read int64
+	offset int64
+	total int64
+	report func(done int64, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	p.report(p.offset + p.read, p.total)
+	return n, err
+}
+
+func printDownloadProgress(done int64, total int64) {
+	const mib = 1 << 20
+	if total > 0 {
+		output.step("\rDownloaded %.1f of %.1f MiB", float64(done) / mib, float64(total) / mib)
+	} else {
+		output.step("\rDownloaded %.1f MiB", float64(done) / mib)
+	}
+}
+
+// downloadResumable downloads url into f. A retry after a connection broke
+// off asks for the rest with a Range header instead of starting over,
+// unless the server ignores it and sends the whole file again.
+func downloadResumable(url string, f *os.File, config *appConfig) error {
+	client := http.Client{Transport: config.downloadTransport()}
+	written := int64(0)
+
+	err := config.retry.do("Template download", func() error {
+		req, err := http.NewRequestWithContext(runCtx, http.MethodGet, url, nil)
+		iferr("Failed to create request: %v\n", err)
+		req.Header.Add("User-Agent", "Go")
+		if written > 0 {
+			req.Header.Add("Range", fmt.Sprintf("bytes=%d-", written))
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return &retryableError{condition: classifyNetError(err), err: err}
+		}
+		defer res.Body.Close()
+
+		switch {
+		case res.StatusCode >= 500:
+			return &retryableError{condition: "5xx", err: fmt.Errorf("server responded with %s", res.Status)}
+		case res.StatusCode == http.StatusTooManyRequests:
+			return &retryableError{condition: "429", err: fmt.Errorf("server responded with %s", res.Status)}
+		case res.StatusCode == http.StatusPartialContent && written > 0:
+		case res.StatusCode == http.StatusOK:
+			if written > 0 {
+				written = 0
+				if err := f.Truncate(0); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("server responded with %s", res.Status)
+		}
+
+		if _, err := f.Seek(written, io.SeekStart); err != nil {
+			return err
+		}
+
+		total := int64(-1)
+		if res.ContentLength >= 0 {
+			total = written + res.ContentLength
+		}
+		body := &progressReader{r: res.Body, offset: written, total: total, report: printDownloadProgress}
+
+		n, err := io.Copy(f, body)
+		written += n
+		output.step("\n")
+		if err != nil {
+			return &retryableError{condition: classifyNetError(err), err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to download template archive: %v", err)
+	}
+	return nil
+}
+
+// readTemplateArchive downloads the tar.gz or zip archive at url and
+// extracts it into a temp dir the caller removes. A single top level dir,
+// like the one GitHub wraps its archives in, is dropped. Nothing is left
+// behind when it fails.
+func readTemplateArchive(url string, config *appConfig) (string, error) {
+	archive, err := os.CreateTemp("", "create-project-archive-")
+	if err != nil {
+		return "", fmt.Errorf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := downloadResumable(url, archive, config); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.MkdirTemp("", "create-project-template-")
+	if err != nil {
+		return "", fmt.Errorf("Failed to create temp dir: %v", err)
+	}
+
+	magic := make([]byte, 4)
+	archive.ReadAt(magic, 0)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		err = extractTarGz(archive, tmp)
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		err = extractZip(archive, tmp)
+	default:
+		err = fmt.Errorf("not a tar.gz or zip archive")
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("Failed to extract template archive: %v", err)
+	}
+
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("Failed to read template archive: %v", err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		inner := filepath.Join(tmp, entries[0].Name())
+		err = os.Rename(inner, tmp + ".root")
+		if err == nil {
+			err = os.Remove(tmp)
+		}
+		if err == nil {
+			err = os.Rename(tmp + ".root", tmp)
+		}
+		if err != nil {
+			os.RemoveAll(tmp)
+			os.RemoveAll(tmp + ".root")
+			return "", fmt.Errorf("Failed to unwrap template archive: %v", err)
+		}
+	}
+	return tmp, nil
+}
+
+// archiveTarget is where an archive entry named name goes under dst, names
+// escaping dst are refused and so are entries at or below a symlink an
+// earlier entry created, which could point them anywhere. Entries in a .git
+// dir are refused too, they could carry hooks or config for the project.
+func archiveTarget(dst string, name string) (string, error) {
+	name = strings.TrimPrefix(filepath.FromSlash(name), string(filepath.Separator))
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("archive entry outside of archive: %s", name)
+	}
+
+	p := dst
+	for _, part := range strings.Split(name, string(filepath.Separator)) {
+		if strings.EqualFold(part, ".git") {
+			return "", fmt.Errorf("archive entry inside .git: %s", name)
+		}
+		p = filepath.Join(p, part)
+		info, err := os.Lstat(p)
+		if err != nil {
+			break
+		}
+		if info.Mode() & fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("archive entry through symlink: %s", name)
+		}
+	}
+	return filepath.Join(dst, name), nil
+}
+
+// writeArchiveLink creates the symlink target pointing at link, if link is
+// relative and stays inside dst as far as its path goes. copyTemplateDir
+// checks where links really resolve to before copying them.
+func writeArchiveLink(dst string, target string, link string) error {
+	rel, err := filepath.Rel(dst, filepath.Join(filepath.Dir(target), link))
+	if filepath.IsAbs(link) || err != nil || !filepath.IsLocal(rel) {
+		name, _ := filepath.Rel(dst, target)
+		output.warn("skipping symlink %s, it points outside of the template\n", name)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
+
+func writeArchiveFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE | os.O_WRONLY | os.O_TRUNC, mode.Perm() | 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func extractTarGz(archive *os.File, dst string) error {
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := archiveTarget(dst, h.Name)
+		if err != nil {
+			return err
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeArchiveFile(target, tr, h.FileInfo().Mode())
+		case tar.TypeSymlink:
+			err = writeArchiveLink(dst, target, h.Linkname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(archive *os.File, dst string) error {
+	info, err := archive.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(archive, info.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		target, err := archiveTarget(dst, zf.Name)
+		if err != nil {
+			return err
+		}
+
+		if zf.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		if zf.Mode() & fs.ModeSymlink != 0 {
+			// Zip stores the link target as the entry's content.
+			var link []byte
+			link, err = io.ReadAll(io.LimitReader(r, 4096))
+			if err == nil {
+				err = writeArchiveLink(dst, target, string(link))
+			}
+		} else {
+			err = writeArchiveFile(target, r, zf.Mode())
+		}
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
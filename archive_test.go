@@ -0,0 +1,235 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archiveEntry is a file, dir (name ending in /) or symlink (link set) of
+// a test archive.
+type archiveEntry struct {
+	name string
+	content string
+	link string
+}
+
+func writeTarGz(t *testing.T, entries []archiveEntry) *os.File {
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		switch {
+		case e.link != "":
+			h = &tar.Header{Name: e.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: e.link}
+		case strings.HasSuffix(e.name, "/"):
+			h = &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e.content))
+	}
+	tw.Close()
+	gz.Close()
+	return tempArchive(t, buf.Bytes())
+}
+
+func writeZip(t *testing.T, entries []archiveEntry) *os.File {
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		h := &zip.FileHeader{Name: e.name}
+		content := e.content
+		switch {
+		case e.link != "":
+			h.SetMode(fs.ModeSymlink | 0777)
+			content = e.link
+		case strings.HasSuffix(e.name, "/"):
+			h.SetMode(fs.ModeDir | 0755)
+		default:
+			h.SetMode(0644)
+		}
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	return tempArchive(t, buf.Bytes())
+}
+
+func tempArchive(t *testing.T, data []byte) *os.File {
+	p := filepath.Join(t.TempDir(), "archive")
+	writeFile(t, p, string(data))
+	f, err := os.Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestExtractArchiveSymlinks(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "docs/"},
+		{name: "docs/guide.md", content: "guide\n"},
+		{name: "guide", link: "docs/guide.md"},
+		{name: "docs/up", link: "../guide"},
+		{name: "absolute", link: "/etc/passwd"},
+		{name: "escape", link: "../outside"},
+		{name: "docs/escape", link: "../../outside"},
+	}
+
+	for name, extract := range map[string]func(*os.File, string) error{
+		"tar.gz": func(f *os.File, dst string) error { return extractTarGz(f, dst) },
+		"zip": func(f *os.File, dst string) error { return extractZip(f, dst) },
+	} {
+		archive := writeTarGz(t, entries)
+		if name == "zip" {
+			archive = writeZip(t, entries)
+		}
+		logged := captureOutput(t)
+
+		dst := t.TempDir()
+		if err := extract(archive, dst); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		for link, want := range map[string]string{"guide": "docs/guide.md", "docs/up": "../guide"} {
+			if got, err := os.Readlink(filepath.Join(dst, link)); err != nil || got != want {
+				t.Errorf("%s: %s links to %q (%v), want %q", name, link, got, err, want)
+			}
+		}
+		for _, link := range []string{"absolute", "escape", "docs/escape"} {
+			if _, err := os.Lstat(filepath.Join(dst, link)); err == nil {
+				t.Errorf("%s: symlink %s out of the archive extracted", name, link)
+			}
+			if !strings.Contains(logged.String(), "skipping symlink " + link) {
+				t.Errorf("%s: no warning about symlink %s:\n%s", name, link, logged)
+			}
+		}
+	}
+}
+
+func TestExtractArchiveThroughSymlink(t *testing.T) {
+	outside := t.TempDir()
+	entries := []archiveEntry{
+		{name: "dir", link: "."},
+		{name: "dir/file", content: "x"},
+	}
+
+	for name, archive := range map[string]*os.File{"tar.gz": writeTarGz(t, entries), "zip": writeZip(t, entries)} {
+		dst := filepath.Join(outside, name)
+		err := extractTarGz(archive, dst)
+		if name == "zip" {
+			err = extractZip(archive, dst)
+		}
+		if err == nil || !strings.Contains(err.Error(), "through symlink") {
+			t.Errorf("%s: extracting through a symlink gave %v", name, err)
+		}
+	}
+}
+
+func TestExtractArchiveGitDir(t *testing.T) {
+	for _, hook := range []string{"template-main/.git/hooks/pre-commit", ".GIT/config"} {
+		entries := []archiveEntry{
+			{name: "template-main/README.md", content: "# Template\n"},
+			{name: hook, content: "#!/bin/sh\n"},
+		}
+
+		for name, archive := range map[string]*os.File{"tar.gz": writeTarGz(t, entries), "zip": writeZip(t, entries)} {
+			dst := t.TempDir()
+			err := extractTarGz(archive, dst)
+			if name == "zip" {
+				err = extractZip(archive, dst)
+			}
+			if err == nil || !strings.Contains(err.Error(), "inside .git") {
+				t.Errorf("%s: extracting %s gave %v", name, hook, err)
+			}
+			if _, err := os.Stat(filepath.Join(dst, hook)); err == nil {
+				t.Errorf("%s: %s extracted", name, hook)
+			}
+		}
+	}
+}
+
+// cutOffServer serves data, breaking off the connection halfway through
+// the first response and honoring Range on the next ones.
+func cutOffServer(t *testing.T, data []byte, ranges *[]string) *httptest.Server {
+	first := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+
+		if first {
+			first = false
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(data[:len(data) / 2])
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+
+		var start int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err != nil {
+			w.Write(data)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data) - 1, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(data[start:])
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadResumableWithTrace(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64 << 10)
+	ranges := []string{}
+	server := cutOffServer(t, data, &ranges)
+
+	logged := captureOutput(t)
+	config := testConfig(nil)
+	config.trace = true
+	config.retry = testRetryBudget(2, 5)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := downloadResumable(server.URL + "/template.tar.gz", f, config); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := os.ReadFile(f.Name()); !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes, want %d", len(got), len(data))
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != fmt.Sprintf("bytes=%d-", len(data) / 2) {
+		t.Errorf("requests with ranges %q, want the second to resume from halfway", ranges)
+	}
+
+	trace := logged.String()
+	if !strings.Contains(trace, "> GET " + server.URL + "/template.tar.gz\n") || !strings.Contains(trace, "< HTTP/1.1 206 Partial Content\n") {
+		t.Errorf("download not traced:\n%.500s", trace)
+	}
+	if strings.Contains(trace, "0123456789abcdef") {
+		t.Errorf("download body in the trace")
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateCacheDir holds the gitignore and license templates
+// --prefetch-templates downloaded, later runs read them from there instead
+// of the network so they also work offline.
+func templateCacheDir() (string, error) {
+	cdir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cdir, "create-project", "templates"), nil
+}
+
+func gitignoreCachePath(dir string, name string) string {
+	return filepath.Join(dir, "gitignore", name)
+}
+
+func licenseCachePath(dir string, key string) string {
+	return filepath.Join(dir, "licenses", strings.ToLower(key) + ".json")
+}
+
+func cachedGitignore(name string) (string, bool) {
+	dir, err := templateCacheDir()
+	if err != nil || !filepath.IsLocal(name) {
+		return "", false
+	}
+	data, err := os.ReadFile(gitignoreCachePath(dir, name))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func cachedLicense(key string) (githubLicense, bool) {
+	license := githubLicense{}
+	dir, err := templateCacheDir()
+	if err != nil || !filepath.IsLocal(key) {
+		return license, false
+	}
+	data, err := os.ReadFile(licenseCachePath(dir, key))
+	if err != nil {
+		return license, false
+	}
+	return license, json.Unmarshal(data, &license) == nil
+}
+
+func writeCacheFile(p string, data []byte) {
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	iferr("Failed to create cache dir: %v\n", err)
+	err = os.WriteFile(p, data, 0644)
+	iferr("Failed to write cache file: %v\n", err)
+}
+
+func fetchGitignoreTemplateNames(config *appConfig) []string {
+	res := githubRequest(http.MethodGet, "/gitignore/templates", nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to fetch gitignore template list", res)
+	}
+
+	names := []string{}
+	decodeResponse(res, &names)
+	return names
+}
+
+func fetchLicenseList(config *appConfig) []githubLicense {
+	res := githubRequest(http.MethodGet, "/licenses", nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to fetch license list", res)
+	}
+
+	licenses := []githubLicense{}
+	decodeResponse(res, &licenses)
+	return licenses
+}
+
+// prefetchTemplates downloads every gitignore and license template github
+// offers into the cache, replacing what an earlier prefetch left there.
+func prefetchTemplates(config *appConfig) {
+	dir, err := templateCacheDir()
+	iferr("Failed to get user cache dir: %v\n", err)
+
+	output.step("Fetching gitignore template list...\n")
+	names := fetchGitignoreTemplateNames(config)
+	for _, name := range names {
+		if !filepath.IsLocal(name) {
+			output.warn("skipping gitignore template with unsafe name %q\n", name)
+			continue
+		}
+		output.step("Fetching gitignore template %s...\n", name)
+		writeCacheFile(gitignoreCachePath(dir, name), []byte(fetchGitignoreTemplate(name, config)))
+	}
+
+	output.step("Fetching license list...\n")
+	licenses := fetchLicenseList(config)
+	for _, l := range licenses {
+		if !filepath.IsLocal(l.Key) {
+			output.warn("skipping license with unsafe key %q\n", l.Key)
+			continue
+		}
+		output.step("Fetching license %s...\n", l.Key)
+		data, err := json.Marshal(fetchLicense(l.Key, config))
+		iferr("Failed to encode license: %v\n", err)
+		writeCacheFile(licenseCachePath(dir, l.Key), data)
+	}
+
+	output.step("Cached %d gitignore templates and %d licenses in %s\n", len(names), len(licenses), dir)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrefetchTemplates(t *testing.T) {
+	env := newTestEnv(t)
+	handleLicenses(env.api)
+	env.api.handle("GET /gitignore/templates", http.StatusOK, `["Go", "../Evil"]`)
+	env.api.handle("GET /gitignore/templates/Go", http.StatusOK, `{"name": "Go", "source": "*.exe\n"}`)
+	env.api.handle("GET /licenses", http.StatusOK, `[{"key": "mit"}, {"key": "apache-2.0"}]`)
+	logged := captureOutput(t)
+
+	prefetchTemplates(testConfig(env.api))
+
+	dir := filepath.Join(env.dir, "cache", "create-project", "templates")
+	if got := readFile(t, filepath.Join(dir, "gitignore", "Go")); got != "*.exe\n" {
+		t.Errorf("cached Go gitignore %q", got)
+	}
+	for _, key := range []string{"mit", "apache-2.0"} {
+		if _, err := os.Stat(filepath.Join(dir, "licenses", key + ".json")); err != nil {
+			t.Errorf("license %s not cached: %v", key, err)
+		}
+	}
+	if !strings.Contains(logged.String(), "Warning: skipping gitignore template with unsafe name \"../Evil\"") {
+		t.Errorf("no warning about the unsafe name:\n%s", logged)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Evil")); !os.IsNotExist(err) {
+		t.Errorf("unsafe name written outside the gitignore dir")
+	}
+
+	if license, ok := cachedLicense("MIT"); !ok || license.Body != "Copyright (c) [year] [fullname]\n" {
+		t.Errorf("cachedLicense(MIT) = %+v, %v", license, ok)
+	}
+
+	fetches := len(env.api.received("GET", "/(gitignore/templates|licenses)/.*"))
+	mustRun(t, "", "", "--gitignore-template", "Go", "--license", "MIT", "offline")
+	if got := len(env.api.received("GET", "/(gitignore/templates|licenses)/.*")); got != fetches {
+		t.Errorf("run fetched %d templates the cache holds", got - fetches)
+	}
+	if got := readFile(t, filepath.Join(env.projPath("offline"), ".gitignore")); !strings.HasSuffix(got, "*.exe\n") {
+		t.Errorf(".gitignore %q, want the cached template", got)
+	}
+}
+
+func TestPrefetchTemplatesFlag(t *testing.T) {
+	env := newTestEnv(t)
+	handleLicenses(env.api)
+	env.api.handle("GET /gitignore/templates", http.StatusOK, `["Go"]`)
+	env.api.handle("GET /gitignore/templates/Go", http.StatusOK, `{"name": "Go", "source": "*.exe\n"}`)
+	env.api.handle("GET /licenses", http.StatusOK, `[{"key": "mit"}]`)
+
+	out := mustRun(t, "", "", "--prefetch-templates", "--trace")
+
+	fetches := env.api.received("GET", "/(gitignore/templates|licenses)(/.*)?")
+	if len(fetches) != 4 {
+		t.Fatalf("configured api got %d template requests, want 4", len(fetches))
+	}
+	for _, r := range fetches {
+		if r.Header.Get("Authorization") != "token " + testToken {
+			t.Errorf("%s %s not authenticated with the configured token", r.Method, r.Path)
+		}
+	}
+	if !strings.Contains(out.stderr, "> GET " + env.api.URL + "/gitignore/templates\n") {
+		t.Errorf("--trace not applied:\n%s", out.stderr)
+	}
+	dir := filepath.Join(env.dir, "cache", "create-project", "templates")
+	if got := readFile(t, filepath.Join(dir, "gitignore", "Go")); got != "*.exe\n" {
+		t.Errorf("cached Go gitignore %q", got)
+	}
+}
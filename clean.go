@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// remoteIsOrphan reports whether owner/repo exists but has no commits, i.e.
+// an earlier run created it and failed before pushing anything.
+func remoteIsOrphan(owner string, repo string, config *appConfig) bool {
+	res := githubRequest(http.MethodGet, fmt.Sprintf("/repos/%s/%s/commits?per_page=1", owner, repo), nil, config)
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusConflict:
+		// GitHub answers 409 for the commits of an empty repository.
+		return true
+	case http.StatusOK, http.StatusNotFound:
+		return false
+	}
+
+	exitWithResponse("Failed to look up repository", res)
+	return false
+}
+
+// localIsLeftover reports whether projPath is an empty dir or a clone of
+// owner/repo, the only states a failed run leaves the project dir in.
+func localIsLeftover(projPath string, owner string, repo string, config *appConfig) bool {
+	entries, err := os.ReadDir(projPath)
+	if err != nil {
+		return false
+	}
+	if len(entries) == 0 {
+		return true
+	}
+
+	// get-url would apply url.<base>.insteadOf, the config has the url as
+	// cloned.
+	cmd := exec.CommandContext(runCtx, "/bin/git", "config", "remote.origin.url")
+	cmd.Dir = projPath
+	out, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(out)) == config.cloneUrl(owner, repo)
+}
+
+func deleteRepo(owner string, repo string, config *appConfig) {
+	res := githubRequest(http.MethodDelete, fmt.Sprintf("/repos/%s/%s", owner, repo), nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusForbidden {
+		exitWithResponse("Failed to delete repository, the token needs the delete_repo scope", res)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		exitWithResponse("Failed to delete repository", res)
+	}
+}
+
+// cleanProject removes what a failed earlier run left behind: a repository
+// nothing was pushed to and the local dir it was cloned into.
+func cleanProject(projName string, projPath string, config *appConfig, opts *appOptions) {
+	orphan := remoteIsOrphan(opts.owner, projName, config)
+	leftover := localIsLeftover(projPath, opts.owner, projName, config)
+
+	// Without an orphaned remote a clone may hold the only copy of some
+	// work, so only an empty dir is removed then.
+	if !orphan && !isEmptyDir(projPath) {
+		leftover = false
+	}
+	if leftover && isAheadOfOrigin(projPath) {
+		output.error("Refusing to remove %s, it has commits not pushed to origin\n", projPath)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(projPath); err == nil && !leftover {
+		output.step("Leaving %s, it may hold work of its own\n", projPath)
+	}
+
+	actions := []string{}
+	if orphan {
+		actions = append(actions, fmt.Sprintf("delete empty repository %s/%s", opts.owner, projName))
+	}
+	if leftover {
+		actions = append(actions, "remove " + projPath)
+	}
+	if len(actions) == 0 {
+		output.step("Nothing to clean\n")
+		return
+	}
+
+	confirm(fmt.Sprintf("Clean up: %s", strings.Join(actions, ", ")), false)
+
+	if orphan {
+		output.step("Deleting repository...\n")
+		deleteRepo(opts.owner, projName, config)
+	}
+	if leftover {
+		output.step("Removing project dir...\n")
+		err := os.RemoveAll(projPath)
+		iferr("Failed to remove project dir: %v\n", err)
+	}
+
+	output.step("Success\n")
+}
+
+func isEmptyDir(p string) bool {
+	entries, err := os.ReadDir(p)
+	return err == nil && len(entries) == 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// leaveHalfCreated sets up what a run failing before the push leaves: an
+// empty repository and a clone of it.
+func leaveHalfCreated(t *testing.T, env *testEnv, name string) string {
+	env.initBare(t, testUser, name)
+	git(t, env.projDir, "clone", "-q", "git@github.com:" + testUser + "/" + name + ".git", name)
+	env.api.handle("GET /repos/" + testUser + "/" + name + "/commits", http.StatusConflict, `{"message": "Git Repository is empty."}`)
+	env.api.handle("DELETE /repos/" + testUser + "/" + name, http.StatusNoContent, "")
+	return env.projPath(name)
+}
+
+func TestCleanOrphan(t *testing.T) {
+	env := newTestEnv(t)
+	projPath := leaveHalfCreated(t, env, "half")
+
+	out := mustRun(t, "", "y\n", "--clean", "half")
+
+	if !strings.Contains(out.stdout, "Clean up: delete empty repository " + testUser + "/half, remove " + projPath) {
+		t.Errorf("cleanup actions not offered:\n%s", out.stdout)
+	}
+	if got := len(env.api.received("DELETE", "^/repos/" + testUser + "/half$")); got != 1 {
+		t.Errorf("repository deleted %d times, want once", got)
+	}
+	if _, err := os.Stat(projPath); err == nil {
+		t.Errorf("leftover clone not removed")
+	}
+}
+
+func TestCleanDeclined(t *testing.T) {
+	env := newTestEnv(t)
+	projPath := leaveHalfCreated(t, env, "half")
+
+	runMain(t, "", "n\n", "--clean", "half")
+	if got := len(env.api.received("DELETE", ".*")); got != 0 {
+		t.Errorf("repository deleted without confirmation")
+	}
+	if _, err := os.Stat(projPath); err != nil {
+		t.Errorf("clone removed without confirmation: %v", err)
+	}
+}
+
+func TestCleanRefusesUnpushedCommits(t *testing.T) {
+	env := newTestEnv(t)
+	projPath := leaveHalfCreated(t, env, "half")
+	writeFile(t, filepath.Join(projPath, "work.txt"), "precious\n")
+	git(t, projPath, "add", ".")
+	git(t, projPath, "commit", "-q", "-m", "work")
+
+	out := runMain(t, "", "y\n", "--clean", "half")
+	if out.code != 1 || !strings.Contains(out.stderr, "Refusing to remove " + projPath + ", it has commits not pushed to origin") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+	if got := len(env.api.received("DELETE", ".*")); got != 0 {
+		t.Errorf("repository deleted despite unpushed commits")
+	}
+	if got := readFile(t, filepath.Join(projPath, "work.txt")); got != "precious\n" {
+		t.Errorf("work.txt = %q", got)
+	}
+}
+
+func TestCleanLeavesPushedRepository(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /repos/" + testUser + "/done/commits", http.StatusOK, `[{"sha": "abc"}]`)
+	writeFile(t, filepath.Join(env.projPath("done"), "README.md"), "# Done\n")
+
+	out := mustRun(t, "", "", "--clean", "done")
+	if !strings.Contains(out.stdout, "Leaving " + env.projPath("done") + ", it may hold work of its own") || !strings.Contains(out.stdout, "Nothing to clean") {
+		t.Errorf("output:\n%s", out.stdout)
+	}
+	if got := len(env.api.received("DELETE", ".*")); got != 0 {
+		t.Errorf("repository with commits deleted")
+	}
+}
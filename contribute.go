@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+)
+
+// contributeBranch is the branch --contribute commits to without --branch.
+const contributeBranch = "create-project"
+
+// createBranch creates branch from start, a commit or remote branch, and
+// checks it out.
+func createBranch(projPath string, branch string, start string) {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "checkout", "-q", "--no-track", "-b", branch, start)
+	cmd.Dir = projPath
+	err := cmd.Run()
+	iferr("Failed to create branch: %v\n", err)
+}
+
+// createPullRequest asks to merge head into base of owner/repo and returns
+// the pull request's url.
+func createPullRequest(owner string, repo string, head string, base string, config *appConfig) string {
+	body := map[string]string{
+		"title": "Add missing project files",
+		"head": head,
+		"base": base,
+		"body": "Adds the project files create-project scaffolds that the repository is missing.",
+	}
+
+	res := githubRequest(http.MethodPost, fmt.Sprintf("/repos/%s/%s/pulls", owner, repo), body, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		exitWithResponse("Failed to open pull request", res)
+	}
+
+	pull := struct {
+		HtmlUrl string `json:"html_url"`
+	}{}
+	decodeResponse(res, &pull)
+	return pull.HtmlUrl
+}
+
+// contributeProject scaffolds into a repository that already exists, e.g.
+// one of an organization the user collaborates on. It is cloned, the files
+// it is missing are committed on a new branch started from --base and a
+// pull request asks to merge them into it. --base defaults to the default
+// branch.
+func contributeProject(
+	projPath string,
+	dirName string,
+	config *appConfig,
+	opts *appOptions,
+) {
+	owner, repo := splitFullName(opts.contribute)
+
+	confirm(fmt.Sprintf("Contribute to %s from %v", opts.contribute, projPath), config.confirmDefault)
+
+	if opts.verifySsh {
+		output.step("Verifying SSH access...\n")
+		verifySsh(config)
+	}
+
+	assets := fetchAssets(config, opts)
+	defer assets.cleanup()
+
+	output.step("Cloning %s into %s...\n", opts.contribute, projPath)
+	cloneRepo(owner, repo, dirName, config)
+
+	base := opts.base
+	if base == "" {
+		base = currentBranch(projPath)
+	}
+	branch := opts.branch
+	if branch == "" {
+		branch = contributeBranch
+	}
+	output.step("Creating branch %s from %s...\n", branch, base)
+	createBranch(projPath, branch, "origin/" + base)
+
+	if !commitMissingFiles(repo, projPath, &assets, config, opts) {
+		output.error("Nothing to add, %s already has all files\n", opts.contribute)
+		os.Exit(1)
+	}
+	if opts.fixIdentity {
+		fixIdentity(projPath)
+	}
+
+	output.step("Pushing %s...\n", branch)
+	pushChanges(projPath, branch, config)
+
+	output.step("Opening pull request...\n")
+	url := createPullRequest(owner, repo, branch, base, config)
+
+	output.step("Success, opened %s\n", url)
+	printSummary(opts, "opened %s for %s at %s on %s", url, opts.contribute, projPath, branch)
+
+	result := newProjectResult(owner, repo, projPath, config)
+	appendToRegistry(result)
+	writeResultFile(result)
+	notify(opts, result)
+
+	if opts.edit {
+		openEditor(projPath, config)
+	}
+}
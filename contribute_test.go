@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContribute(t *testing.T) {
+	env := newTestEnv(t)
+	bare := env.seedBare(t, "acme", "shared", "existing")
+	env.api.handle("POST /repos/acme/shared/pulls", http.StatusCreated, `{"html_url": "https://github.com/acme/shared/pull/1"}`)
+
+	out := mustRun(t, "", "", "--contribute", "acme/shared")
+
+	if creates := env.api.received("POST", "/(user|orgs/.*)/repos"); len(creates) > 0 {
+		t.Errorf("contribute created a repository: %v", creates)
+	}
+	if got := git(t, bare, "log", "--format=%s", "create-project"); got != "add missing project files\nexisting" {
+		t.Errorf("pushed branch has commits %q, want one on top of the existing", got)
+	}
+	if files := git(t, bare, "ls-tree", "--name-only", "create-project"); !strings.Contains(files, "README.md") {
+		t.Errorf("pushed branch misses README.md:\n%s", files)
+	}
+
+	pulls := env.api.received("POST", "/repos/acme/shared/pulls")
+	if len(pulls) != 1 {
+		t.Fatalf("%d pull requests opened, want 1", len(pulls))
+	}
+	if body := pulls[0].json(t); body["head"] != "create-project" || body["base"] != "main" {
+		t.Errorf("pull request %v, want create-project into main", body)
+	}
+	if !strings.Contains(out.stdout, "Success, opened https://github.com/acme/shared/pull/1\n") {
+		t.Errorf("pull request url not printed:\n%s", out.stdout)
+	}
+}
+
+func TestContributeNothingMissing(t *testing.T) {
+	env := newTestEnv(t)
+	bare := env.seedBare(t, "acme", "complete", "existing")
+	work := t.TempDir()
+	git(t, "", "clone", "-q", bare, work)
+	writeFile(t, filepath.Join(work, "README.md"), "# Complete\n")
+	writeFile(t, filepath.Join(work, ".gitignore"), "")
+	git(t, work, "add", ".")
+	git(t, work, "commit", "-q", "-m", "files")
+	git(t, work, "push", "-q", "origin", "main")
+
+	out := runMain(t, "", "", "--contribute", "acme/complete")
+	if out.code != 1 || !strings.Contains(out.stderr, "Nothing to add, acme/complete already has all files\n") {
+		t.Errorf("exit %d, stderr:\n%s", out.code, out.stderr)
+	}
+	if pulls := env.api.received("POST", "/repos/.*/pulls"); len(pulls) > 0 {
+		t.Errorf("pull request opened without changes")
+	}
+}
+
+func TestContributeBaseBranch(t *testing.T) {
+	env := newTestEnv(t)
+	bare := env.seedBare(t, "acme", "shared", "existing")
+	work := t.TempDir()
+	git(t, "", "clone", "-q", bare, work)
+	git(t, work, "checkout", "-q", "-b", "develop")
+	git(t, work, "commit", "-q", "--allow-empty", "-m", "on develop")
+	git(t, work, "push", "-q", "origin", "develop")
+	env.api.handle("POST /repos/acme/shared/pulls", http.StatusCreated, `{"html_url": "https://github.com/acme/shared/pull/2"}`)
+
+	mustRun(t, "", "", "--contribute", "acme/shared", "--base", "develop", "--branch", "feature/x")
+
+	if got := git(t, bare, "rev-parse", "feature/x~1"); got != git(t, bare, "rev-parse", "develop") {
+		t.Errorf("feature/x does not start from develop")
+	}
+	if got := git(t, bare, "log", "--format=%s", "main"); got != "existing" {
+		t.Errorf("main changed: %q", got)
+	}
+	if got := git(t, env.projPath("shared"), "symbolic-ref", "--short", "HEAD"); got != "feature/x" {
+		t.Errorf("clone is on %q, want feature/x", got)
+	}
+
+	pulls := env.api.received("POST", "/repos/acme/shared/pulls")
+	if len(pulls) != 1 {
+		t.Fatalf("%d pull requests opened, want 1", len(pulls))
+	}
+	if body := pulls[0].json(t); body["head"] != "feature/x" || body["base"] != "develop" {
+		t.Errorf("pull request %v, want feature/x into develop", body)
+	}
+}
+
+func TestBaseRequiresContribute(t *testing.T) {
+	newTestEnv(t)
+	out := runMain(t, "", "", "--base", "develop", "demo")
+	if out.code != 1 || out.stderr != "--base requires --contribute\n" {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// generateDeployKey creates an ed25519 keypair at keyPath and keyPath.pub
+// and returns the public key.
+func generateDeployKey(keyPath string, comment string) string {
+	cmd := exec.CommandContext(runCtx, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", comment, "-f", keyPath)
+	err := cmd.Run()
+	iferr("Failed to generate deploy key: %v\n", err)
+
+	err = os.Chmod(keyPath, 0600)
+	iferr("Failed to change file mode: %v\n", err)
+
+	pub, err := os.ReadFile(keyPath + ".pub")
+	iferr("Failed to read deploy key: %v\n", err)
+
+	return strings.TrimSpace(string(pub))
+}
+
+func addDeployKey(owner string, repo string, key string, readOnly bool, config *appConfig) {
+	res := githubRequest(
+		http.MethodPost,
+		fmt.Sprintf("/repos/%s/%s/keys", owner, repo),
+		map[string]any{
+			"title": "create-project deploy key",
+			"key": key,
+			"read_only": readOnly,
+		},
+		config,
+	)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		exitWithResponse("Failed to add deploy key", res)
+	}
+}
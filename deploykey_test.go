@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeployKey(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /repos/" + testUser + "/keyed/keys", http.StatusCreated, `{"id": 1}`)
+	keyPath := filepath.Join(env.dir, "deploy_key")
+
+	mustRun(t, "", "", "--deploy-key", keyPath, "keyed")
+
+	keys := env.api.received("POST", "/repos/" + testUser + "/keyed/keys")
+	if len(keys) != 1 {
+		t.Fatalf("got %d key requests, want 1", len(keys))
+	}
+	body := keys[0].json(t)
+	pub := strings.TrimSpace(readFile(t, keyPath + ".pub"))
+	if body["key"] != pub || !strings.HasPrefix(pub, "ssh-ed25519 ") {
+		t.Errorf("key = %v, want the ed25519 public key %q", body["key"], pub)
+	}
+	if body["read_only"] != true || body["title"] == "" {
+		t.Errorf("key body = %v", body)
+	}
+	if !strings.HasSuffix(pub, " " + testUser + "/keyed") {
+		t.Errorf("public key comment does not name the repository: %q", pub)
+	}
+
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("private key mode %v, want 0600", info.Mode().Perm())
+	}
+	if key := readFile(t, keyPath); !strings.Contains(key, "PRIVATE KEY") {
+		t.Errorf("private key file does not hold a private key")
+	}
+}
+
+func TestDeployKeyWrite(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /repos/" + testUser + "/keyed/keys", http.StatusCreated, `{"id": 1}`)
+	mustRun(t, "", "", "--deploy-key", filepath.Join(env.dir, "deploy_key"), "--deploy-key-write", "keyed")
+
+	if body := env.api.received("POST", "/repos/" + testUser + "/keyed/keys")[0].json(t); body["read_only"] != false {
+		t.Errorf("read_only = %v, want false", body["read_only"])
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// authenticatedUser returns the login the token belongs to, or "" when the
+// api rejects the token.
+func authenticatedUser(config *appConfig) string {
+	res := githubRequest(http.MethodGet, "/user", nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return ""
+	}
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to look up authenticated user", res)
+	}
+
+	user := struct {
+		Login string `json:"login"`
+	}{}
+	decodeResponse(res, &user)
+	return user.Login
+}
+
+// isActiveOrgMember reports whether the authenticated user is an active
+// member of org.
+func isActiveOrgMember(org string, config *appConfig) bool {
+	res := githubRequest(http.MethodGet, "/user/memberships/orgs/" + org, nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusForbidden {
+		return false
+	}
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to look up organization membership", res)
+	}
+
+	membership := struct {
+		State string `json:"state"`
+	}{}
+	decodeResponse(res, &membership)
+	return membership.State == "active"
+}
+
+// validateRemote runs the read-only checks of --dry-run --validate-remote:
+// the token is accepted and belongs to gh_username, the repository name is
+// free or the repository worked on exists, and the user is a member of the
+// organization creating it. Every problem found is printed and it exits
+// with 1 if there were any.
+func validateRemote(projName string, config *appConfig, opts *appOptions) {
+	problems := []string{}
+
+	output.step("Checking token...\n")
+	login := authenticatedUser(config)
+	switch {
+	case login == "":
+		problems = append(problems, "token was rejected\n" + tokenScopeHint)
+	case !strings.EqualFold(login, config.ghUsername):
+		problems = append(problems, fmt.Sprintf("token belongs to %s, not gh_username %s", login, config.ghUsername))
+	}
+
+	switch {
+	case opts.contribute != "":
+		owner, repo := splitFullName(opts.contribute)
+		output.step("Checking repository %s...\n", opts.contribute)
+		if !repoExists(owner, repo, config) {
+			problems = append(problems, fmt.Sprintf("repository %s not found", opts.contribute))
+		}
+	case opts.reinitExisting:
+	default:
+		if opts.fork != "" {
+			owner, repo := splitFullName(opts.fork)
+			output.step("Checking repository %s...\n", opts.fork)
+			if !repoExists(owner, repo, config) {
+				problems = append(problems, fmt.Sprintf("repository %s not found", opts.fork))
+			}
+		}
+
+		output.step("Checking name %s/%s...\n", opts.owner, projName)
+		if opts.since == 0 && repoExists(opts.owner, projName, config) {
+			problems = append(problems, fmt.Sprintf("%s/%s is taken", opts.owner, projName))
+		}
+
+		if opts.ownerType == "org" {
+			output.step("Checking membership of %s...\n", opts.owner)
+			if !isActiveOrgMember(opts.owner, config) {
+				problems = append(problems, fmt.Sprintf("not an active member of organization %s", opts.owner))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		for _, p := range problems {
+			output.error("%s\n", p)
+		}
+		os.Exit(1)
+	}
+
+	output.step("Remote checks passed\n")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// assertOnlyGets fails t for every request to api that is not a GET.
+func assertOnlyGets(t *testing.T, api *mockApi) {
+	t.Helper()
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	for _, r := range api.requests {
+		if r.Method != http.MethodGet {
+			t.Errorf("%s %s issued in dry run", r.Method, r.Path)
+		}
+	}
+}
+
+func TestValidateRemote(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /user", http.StatusOK, `{"login": "` + testUser + `"}`)
+	env.api.handle("GET /user/memberships/orgs/acme", http.StatusOK, `{"state": "active"}`)
+
+	out := mustRun(t, "", "", "--dry-run", "--validate-remote", "--owner", "acme", "--owner-type", "org", "app")
+
+	if !strings.Contains(out.stdout, "Remote checks passed\n") {
+		t.Errorf("checks did not pass:\n%s", out.stdout)
+	}
+	for _, path := range []string{"/user", "/repos/acme/app", "/user/memberships/orgs/acme"} {
+		if len(env.api.received("GET", path)) != 1 {
+			t.Errorf("GET %s not checked", path)
+		}
+	}
+	assertOnlyGets(t, env.api)
+}
+
+func TestValidateRemoteProblems(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /user", http.StatusOK, `{"login": "someone-else"}`)
+	env.api.handle("GET /repos/acme/app", http.StatusOK, `{"name": "app"}`)
+	env.api.handle("GET /user/memberships/orgs/acme", http.StatusOK, `{"state": "pending"}`)
+
+	out := runMain(t, "", "", "--dry-run", "--validate-remote", "--owner", "acme", "--owner-type", "org", "app")
+
+	want := "token belongs to someone-else, not gh_username " + testUser + "\n" +
+		"acme/app is taken\n" +
+		"not an active member of organization acme\n"
+	if out.code != 1 || !strings.HasSuffix(out.stderr, want) {
+		t.Errorf("exit %d, stderr %q, want every problem", out.code, out.stderr)
+	}
+	assertOnlyGets(t, env.api)
+}
+
+func TestValidateRemoteRequiresDryRun(t *testing.T) {
+	newTestEnv(t)
+	out := runMain(t, "", "", "--validate-remote", "app")
+	if out.code != 1 || out.stderr != "--validate-remote requires --dry-run\n" {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import "strings"
+
+// emojiShortcodes maps the :shortcode: names most used in project
+// descriptions to their emoji.
+var emojiShortcodes = map[string]string{
+	"rocket": "\U0001F680",
+	"sparkles": "\u2728",
+	"tada": "\U0001F389",
+	"fire": "\U0001F525",
+	"zap": "\u26A1",
+	"star": "\u2B50",
+	"heart": "\u2764\uFE0F",
+	"bug": "\U0001F41B",
+	"wrench": "\U0001F527",
+	"hammer": "\U0001F528",
+	"package": "\U0001F4E6",
+	"books": "\U0001F4DA",
+	"memo": "\U0001F4DD",
+	"construction": "\U0001F6A7",
+	"lock": "\U0001F512",
+	"globe_with_meridians": "\U0001F310",
+	"computer": "\U0001F4BB",
+	"robot": "\U0001F916",
+	"snake": "\U0001F40D",
+	"crab": "\U0001F980",
+	"whale": "\U0001F433",
+	"gear": "\u2699\uFE0F",
+	"white_check_mark": "\u2705",
+	"warning": "\u26A0\uFE0F",
+}
+
+// expandShortcodes replaces known :shortcode: names in s with their emoji,
+// unknown ones are left as written.
+func expandShortcodes(s string) string {
+	if !strings.Contains(s, ":") {
+		return s
+	}
+	for name, emoji := range emojiShortcodes {
+		s = strings.ReplaceAll(s, ":" + name + ":", emoji)
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandShortcodes(t *testing.T) {
+	tests := []struct {
+		in string
+		want string
+	}{
+		{":rocket: Fast tool", "\U0001F680 Fast tool"},
+		{"Ships :sparkles: and :bug:", "Ships ✨ and \U0001F41B"},
+		{"Keeps :unknown: and 10:30", "Keeps :unknown: and 10:30"},
+		{"no shortcodes", "no shortcodes"},
+	}
+
+	for _, tt := range tests {
+		if got := expandShortcodes(tt.in); got != tt.want {
+			t.Errorf("expandShortcodes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDescriptionShortcodes(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--description", ":rocket: Launches things", "launcher")
+
+	creates := env.api.received("POST", "^/user/repos$")
+	if len(creates) != 1 {
+		t.Fatalf("got %d create requests", len(creates))
+	}
+	if got := creates[0].json(t)["description"]; got != "\U0001F680 Launches things" {
+		t.Errorf("api description = %q", got)
+	}
+
+	readme := readFile(t, filepath.Join(env.projPath("launcher"), "README.md"))
+	if !strings.Contains(readme, "\U0001F680 Launches things") || strings.Contains(readme, ":rocket:") {
+		t.Errorf("README.md shortcode not expanded:\n%s", readme)
+	}
+}
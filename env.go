@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// loadEnvFile reads dotenv style KEY=VALUE lines. Blank lines, # comments
+// and an export prefix are skipped, values may be single quoted (taken
+// literally) or double quoted (with \n, \t, \" and \\ escapes).
+func loadEnvFile(path string) map[string]string {
+	f, err := os.Open(path)
+	iferr("Failed to open env file: %v\n", err)
+	defer f.Close()
+
+	vars := map[string]string{}
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(stripBom(s.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		k, v, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" || strings.ContainsAny(k, " \t") {
+			output.error("Invalid env file line %d, expected KEY=VALUE: %s\n", n, line)
+			os.Exit(1)
+		}
+
+		value, ok := parseEnvValue(strings.TrimSpace(v))
+		if !ok {
+			output.error("Invalid env file line %d, unterminated quote: %s\n", n, line)
+			os.Exit(1)
+		}
+		vars[k] = value
+	}
+	iferr("Failed to read env file: %v\n", s.Err())
+
+	return vars
+}
+
+func parseEnvValue(v string) (string, bool) {
+	if strings.HasPrefix(v, "'") {
+		end := strings.Index(v[1:], "'")
+		if end < 0 {
+			return "", false
+		}
+		return v[1:end + 1], true
+	}
+
+	if strings.HasPrefix(v, "\"") {
+		b := strings.Builder{}
+		for i := 1; i < len(v); i++ {
+			switch c := v[i]; {
+			case c == '"':
+				return b.String(), true
+			case c == '\\' && i + 1 < len(v):
+				i++
+				switch v[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(v[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", false
+	}
+
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v), true
+}
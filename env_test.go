@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadEnvFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, p, strings.Join([]string{
+		"# seeded config",
+		"",
+		"PORT=8080",
+		"export REGION = eu-west-1 # nearest",
+		"GREETING=\"hello\\n\\\"world\\\"\"",
+		"LITERAL='a\\nb # c'",
+		"EMPTY=",
+	}, "\n"))
+
+	want := map[string]string{
+		"PORT": "8080",
+		"REGION": "eu-west-1",
+		"GREETING": "hello\n\"world\"",
+		"LITERAL": "a\\nb # c",
+		"EMPTY": "",
+	}
+	got := loadEnvFile(p)
+	if len(got) != len(want) {
+		t.Errorf("loaded %v", got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestInvalidEnvFile(t *testing.T) {
+	tests := []struct {
+		content string
+		want string
+	}{
+		{"PORT 8080", "Invalid env file line 1, expected KEY=VALUE"},
+		{"# ok\nNAME=\"open", "Invalid env file line 2, unterminated quote"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), ".env")
+			writeFile(t, p, tt.content)
+			out, code := expectExit(t, func() { loadEnvFile(p) })
+			if code != 1 || !strings.Contains(out, tt.want) {
+				t.Errorf("exit %d, output %q", code, out)
+			}
+		})
+	}
+}
+
+func TestEnvFileTemplateVars(t *testing.T) {
+	env := newTestEnv(t)
+	template := initTemplateRepo(t, map[string]string{
+		templateManifestName: `{"variables": [{"name": "port"}, {"name": "region"}]}`,
+		"config.env": "PORT={{port}}\nREGION={{region}}\n",
+	})
+	envFile := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, envFile, "port=8080\nregion=eu-west-1\n")
+
+	mustRun(t, "", "", "--template-git", template, "--env-file", envFile, "--var", "region=us-east-1", "seeded")
+
+	if got := readFile(t, filepath.Join(env.projPath("seeded"), "config.env")); got != "PORT=8080\nREGION=us-east-1\n" {
+		t.Errorf("config.env = %q, want the env file's port and the --var region", got)
+	}
+}
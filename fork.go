@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func splitFullName(s string) (string, string) {
+	owner, name, ok := strings.Cut(s, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		output.error("Invalid repository, expected OWNER/NAME: %s\n", s)
+		os.Exit(1)
+	}
+	return validateOwner(owner), validateProjName(name)
+}
+
+// forkRepo forks upstream into opts.owner and returns the fork's name, which
+// GitHub may change when the name is already taken.
+func forkRepo(upstream string, name string, config *appConfig, opts *appOptions) string {
+	body := map[string]any{"name": name}
+	if opts.ownerType == "org" {
+		body["organization"] = opts.owner
+	}
+
+	res := githubRequest(http.MethodPost, "/repos/" + upstream + "/forks", body, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		exitWithResponse("Failed to fork repository", res)
+	}
+
+	fork := struct {
+		Name string `json:"name"`
+	}{}
+	decodeResponse(res, &fork)
+	return fork.Name
+}
+
+func addUpstreamRemote(projPath string, upstream string, config *appConfig) {
+	cmd := exec.CommandContext(
+		runCtx,
+		"/bin/git",
+		"remote",
+		"add",
+		"upstream",
+		fmt.Sprintf("git@%s:%s.git", config.gitHost(), upstream),
+	)
+	cmd.Dir = projPath
+	err := cmd.Run()
+	iferr("Failed to add upstream remote: %v\n", err)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// seedBare pushes a commit with subject to the bare repository of
+// owner/name.
+func (env *testEnv) seedBare(t *testing.T, owner string, name string, subject string) string {
+	bare := env.initBare(t, owner, name)
+	work := t.TempDir()
+	git(t, work, "init", "-q", "-b", "main")
+	git(t, work, "commit", "-q", "--allow-empty", "-m", subject)
+	git(t, work, "push", "-q", bare, "main")
+	git(t, bare, "symbolic-ref", "HEAD", "refs/heads/main")
+	return bare
+}
+
+// handleFork forks owner/name on the mock api, naming the fork forkName
+// the way GitHub renames a fork whose name is taken.
+func (env *testEnv) handleFork(t *testing.T, owner string, name string, forkName string) {
+	env.api.handleFunc("POST /repos/" + owner + "/" + name + "/forks", func(w http.ResponseWriter, r *http.Request) {
+		upstream := filepath.Join(env.remotes, owner, name + ".git")
+		git(t, "", "clone", "-q", "--bare", upstream, filepath.Join(env.remotes, testUser, forkName + ".git"))
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, `{"name": %q, "full_name": "%s/%s"}`, forkName, testUser, forkName)
+	})
+}
+
+func TestFork(t *testing.T) {
+	env := newTestEnv(t)
+	env.seedBare(t, "upstream-org", "lib", "upstream commit")
+	env.handleFork(t, "upstream-org", "lib", "lib-1")
+
+	out := mustRun(t, "", "", "--fork", "upstream-org/lib")
+	projPath := env.projPath("lib")
+
+	forks := env.api.received("POST", "/repos/upstream-org/lib/forks")
+	if len(forks) != 1 {
+		t.Fatalf("got %d fork requests, want 1", len(forks))
+	}
+	if body := forks[0].json(t); body["name"] != "lib" || body["organization"] != nil {
+		t.Errorf("fork body = %v", body)
+	}
+	if creates := env.api.received("POST", "/user/repos"); len(creates) > 0 {
+		t.Errorf("fork also created a repository")
+	}
+
+	remotes := map[string]string{
+		"origin": "git@github.com:" + testUser + "/lib-1.git",
+		"upstream": "git@github.com:upstream-org/lib.git",
+	}
+	for remote, want := range remotes {
+		if got := git(t, projPath, "config", "remote." + remote + ".url"); got != want {
+			t.Errorf("%s = %q, want %q", remote, got, want)
+		}
+	}
+	if got := git(t, projPath, "log", "-1", "--format=%s"); got != "upstream commit" {
+		t.Errorf("clone is at %q, want the upstream history", got)
+	}
+	if !strings.Contains(out.stdout, "Success") {
+		t.Errorf("output:\n%s", out.stdout)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// fundingPlatforms lists the FUNDING.yml keys in the order GitHub documents
+// them. github and custom take several accounts, the rest a single one.
+var fundingPlatforms = []string{
+	"github",
+	"patreon",
+	"open_collective",
+	"ko_fi",
+	"tidelift",
+	"community_bridge",
+	"liberapay",
+	"issuehunt",
+	"lfx_crowdfunding",
+	"polar",
+	"buy_me_a_coffee",
+	"thanks_dev",
+	"custom",
+}
+
+func isListFundingPlatform(platform string) bool {
+	return platform == "github" || platform == "custom"
+}
+
+// parseFunding parses a --funding PLATFORM:ACCOUNT value into funding.
+func parseFunding(s string, funding map[string][]string) {
+	platform, account, ok := strings.Cut(s, ":")
+	platform = strings.ReplaceAll(strings.ToLower(platform), "-", "_")
+	if !ok || account == "" || !slices.Contains(fundingPlatforms, platform) {
+		output.error(
+			"Invalid funding, expected PLATFORM:ACCOUNT with PLATFORM one of %s: %s\n",
+			strings.Join(fundingPlatforms, ", "),
+			s,
+		)
+		os.Exit(1)
+	}
+
+	if len(funding[platform]) > 0 && !isListFundingPlatform(platform) {
+		output.error("Funding platform %s takes a single account\n", platform)
+		os.Exit(1)
+	}
+	funding[platform] = append(funding[platform], account)
+}
+
+func createFunding(projPath string, funding map[string][]string) {
+	dir := filepath.Join(projPath, ".github")
+	err := os.MkdirAll(dir, 0755)
+	iferr("Failed to create .github dir: %v\n", err)
+
+	f := createFile(filepath.Join(dir, "FUNDING.yml"))
+	defer f.Close()
+
+	for _, platform := range fundingPlatforms {
+		accounts := funding[platform]
+		switch {
+		case len(accounts) == 0:
+		case isListFundingPlatform(platform):
+			quoted := []string{}
+			for _, a := range accounts {
+				quoted = append(quoted, fmt.Sprintf("%q", a))
+			}
+			fmt.Fprintf(f, "%s: [%s]\n", platform, strings.Join(quoted, ", "))
+		default:
+			fmt.Fprintf(f, "%s: %q\n", platform, accounts[0])
+		}
+	}
+}
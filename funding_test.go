@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFunding(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--funding", "github:alice", "--funding", "ko-fi:bob", "--funding", "GitHub:carol", "funded")
+
+	got := readFile(t, filepath.Join(env.projPath("funded"), ".github", "FUNDING.yml"))
+	want := "github: [\"alice\", \"carol\"]\nko_fi: \"bob\"\n"
+	if got != want {
+		t.Errorf("FUNDING.yml %q, want %q", got, want)
+	}
+	if files := git(t, env.projPath("funded"), "ls-files"); !strings.Contains(files, ".github/FUNDING.yml") {
+		t.Errorf("FUNDING.yml not committed:\n%s", files)
+	}
+}
+
+func TestInvalidFunding(t *testing.T) {
+	tests := []struct {
+		arg string
+		want string
+	}{
+		{"github", "Invalid funding, expected PLATFORM:ACCOUNT"},
+		{"venmo:alice", "Invalid funding, expected PLATFORM:ACCOUNT"},
+		{"patreon:alice,patreon:bob", "Funding platform patreon takes a single account\n"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			logged, code := expectExit(t, func() {
+				funding := map[string][]string{}
+				for _, s := range strings.Split(tt.arg, ",") {
+					parseFunding(s, funding)
+				}
+			})
+			if code != 1 || !strings.Contains(logged, tt.want) {
+				t.Errorf("%s: exit %d, printed %q, want %q", tt.arg, code, logged, tt.want)
+			}
+		})
+	}
+}
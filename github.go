@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const githubApiUrl = "https://api.github.com"
+
+// apiUrl is the base url requests go to, api_url in the config points it at
+// a self-hosted forge or a mock.
+func (c *appConfig) apiUrl() string {
+	if c.apiBaseUrl != "" {
+		return c.apiBaseUrl
+	}
+	return githubApiUrl
+}
+
+// parseApiUrl validates an api url config field and drops a trailing slash.
+func parseApiUrl(name string, s string) string {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		output.error("Invalid %s, expected http(s) url: %s\n", name, s)
+		os.Exit(1)
+	}
+	return strings.TrimSuffix(s, "/")
+}
+
+var cloneHostPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+
+// parseCloneHost validates a clone host config field, the host name ssh
+// clones from.
+func parseCloneHost(name string, s string) string {
+	if !cloneHostPattern.MatchString(s) {
+		output.error("Invalid %s, expected host name like github.com: %s\n", name, s)
+		os.Exit(1)
+	}
+	return s
+}
+
+// gitHost is the host projects are cloned from and browsed at: the clone
+// host of the config, or else github.com for github and the host of the api
+// url for gitea, which serves both from one place.
+func (c *appConfig) gitHost() string {
+	if c.cloneHost != "" {
+		return c.cloneHost
+	}
+	if c.host == "gitea" {
+		u, _ := url.Parse(c.apiUrl())
+		return u.Hostname()
+	}
+	return "github.com"
+}
+
+// cloneUrl is the ssh url of owner/name on the git host.
+func (c *appConfig) cloneUrl(owner string, name string) string {
+	return fmt.Sprintf("git@%s:%s/%s.git", c.gitHost(), owner, name)
+}
+
+// parseEndpointPath validates the create_endpoint config field, an absolute
+// path that may contain {owner}.
+func parseEndpointPath(s string) string {
+	u, err := url.Parse(strings.ReplaceAll(s, "{owner}", "owner"))
+	valid := err == nil && u.Scheme == "" && u.Host == "" && u.RawQuery == "" && u.Fragment == "" &&
+		strings.HasPrefix(s, "/") && !strings.ContainsAny(s, " \t") && !slices.Contains(strings.Split(s, "/"), "..")
+	if !valid {
+		output.error("Invalid create_endpoint, expected absolute path: %s\n", s)
+		os.Exit(1)
+	}
+	return s
+}
+
+// githubRequest retries transient failures within the run's retry budget.
+// When a failed response is not retried any further it is returned so
+// callers report it like any other failure. A read-only config allows GET
+// requests only.
+func githubRequest(method string, endpoint string, body any, config *appConfig) *http.Response {
+	if config.readOnly && method != http.MethodGet {
+		output.error("Refusing %s %s, --dry-run only reads\n", method, endpoint)
+		os.Exit(1)
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		iferr("Failed to encode request body: %v\n", err)
+	}
+
+	client := http.Client{Transport: config.httpTransport()}
+
+	var res *http.Response
+	err := config.retry.do(method + " " + endpoint, func() error {
+		if res != nil {
+			res.Body.Close()
+			res = nil
+		}
+
+		req, err := http.NewRequestWithContext(runCtx, method, config.apiUrl() + endpoint, bytes.NewReader(payload))
+		iferr("Failed to create request: %v\n", err)
+
+		req.Header.Add("User-Agent", "Go")
+		if config.ghApiKey != "" {
+			req.Header.Add("Authorization", "token " + config.ghApiKey)
+		}
+
+		res, err = client.Do(req)
+		if err != nil {
+			return &retryableError{condition: classifyNetError(err), err: err}
+		}
+
+		switch {
+		case res.StatusCode >= 500:
+			return &retryableError{condition: "5xx", err: fmt.Errorf("server responded with %s", res.Status)}
+		case res.StatusCode == http.StatusTooManyRequests:
+			return &retryableError{condition: "429", err: fmt.Errorf("server responded with %s", res.Status)}
+		}
+		return nil
+	})
+
+	if res == nil {
+		iferr("Failed to execute request: %v\n", err)
+	}
+
+	return res
+}
+
+// exitWithResponse prints msg followed by the indented response body and
+// exits. It is used when the api answers with an unexpected status.
+func exitWithResponse(msg string, res *http.Response) {
+	output.error("%s\n", msg)
+
+	data, err := io.ReadAll(res.Body)
+	iferr("Failed to read response body: %v\n", err)
+
+	pretty := bytes.Buffer{}
+	err = json.Indent(&pretty, data, "", "  ")
+	iferr("Failed to indent json: %v\n", err)
+
+	output.error("%s\n", pretty.String())
+	os.Exit(1)
+}
+
+func decodeResponse(res *http.Response, out any) {
+	err := json.NewDecoder(res.Body).Decode(out)
+	iferr("Failed to decode response body: %v\n", err)
+}
+
+func fetchGitignoreTemplate(name string, config *appConfig) string {
+	res := githubRequest(http.MethodGet, "/gitignore/templates/" + name, nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		output.error("Unknown gitignore template: %s\n", name)
+		os.Exit(1)
+	}
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to fetch gitignore template", res)
+	}
+
+	template := struct {
+		Source string `json:"source"`
+	}{}
+	decodeResponse(res, &template)
+	return template.Source
+}
+
+func createActionsVariable(owner string, repo string, name string, value string, config *appConfig) {
+	res := githubRequest(
+		http.MethodPost,
+		fmt.Sprintf("/repos/%s/%s/actions/variables", owner, repo),
+		map[string]string{"name": name, "value": value},
+		config,
+	)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		exitWithResponse("Failed to create actions variable " + name, res)
+	}
+}
+
+type secretsPublicKey struct {
+	KeyId string `json:"key_id"`
+	Key string `json:"key"`
+}
+
+// fetchSecretsPublicKey fetches the key actions secrets of owner/repo are
+// encrypted to.
+func fetchSecretsPublicKey(owner string, repo string, config *appConfig) secretsPublicKey {
+	res := githubRequest(http.MethodGet, fmt.Sprintf("/repos/%s/%s/actions/secrets/public-key", owner, repo), nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to fetch actions secrets public key", res)
+	}
+
+	key := secretsPublicKey{}
+	decodeResponse(res, &key)
+	return key
+}
+
+// createActionsSecret stores value as a sealed box to the repository key,
+// the api never sees it in the clear.
+func createActionsSecret(owner string, repo string, name string, value string, key secretsPublicKey, config *appConfig) {
+	pub, err := base64.StdEncoding.DecodeString(key.Key)
+	iferr("Failed to decode actions secrets public key: %v\n", err)
+
+	box, err := sealBox([]byte(value), pub)
+	iferr("Failed to encrypt actions secret: %v\n", err)
+
+	res := githubRequest(
+		http.MethodPut,
+		fmt.Sprintf("/repos/%s/%s/actions/secrets/%s", owner, repo, name),
+		map[string]string{
+			"encrypted_value": base64.StdEncoding.EncodeToString(box),
+			"key_id": key.KeyId,
+		},
+		config,
+	)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusNoContent {
+		exitWithResponse("Failed to create actions secret " + name, res)
+	}
+}
+
+// detectOwnerType asks the api whether owner is a user or an organization,
+// since repositories for each are created through different endpoints. It
+// returns "" when there is no such owner.
+func detectOwnerType(owner string, config *appConfig) string {
+	res := githubRequest(http.MethodGet, "/users/" + owner, nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return ""
+	}
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to detect owner type", res)
+	}
+
+	user := struct {
+		Type string `json:"type"`
+	}{}
+	decodeResponse(res, &user)
+
+	if user.Type == "Organization" {
+		return "org"
+	}
+	return "user"
+}
+
+func createRepoEndpoint(owner string, ownerType string, config *appConfig) string {
+	if config.createEndpoint != "" {
+		return strings.ReplaceAll(config.createEndpoint, "{owner}", url.PathEscape(owner))
+	}
+	if ownerType == "org" {
+		return "/orgs/" + owner + "/repos"
+	}
+	return "/user/repos"
+}
+
+// pruneLabels deletes every label of the repository, which right after
+// creation are the defaults GitHub seeds.
+func pruneLabels(owner string, repo string, config *appConfig) {
+	endpoint := fmt.Sprintf("/repos/%s/%s/labels", owner, repo)
+
+	res := githubRequest(http.MethodGet, endpoint + "?per_page=100", nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to list labels", res)
+	}
+
+	labels := []struct {
+		Name string `json:"name"`
+	}{}
+	decodeResponse(res, &labels)
+
+	for _, label := range labels {
+		res := githubRequest(http.MethodDelete, endpoint + "/" + url.PathEscape(label.Name), nil, config)
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent {
+			output.error("Failed to delete label %s: %s\n", label.Name, res.Status)
+			os.Exit(1)
+		}
+	}
+}
+
+// markAsTemplate turns owner/repo into a template repository other
+// repositories can be generated from.
+func markAsTemplate(owner string, repo string, config *appConfig) {
+	body := map[string]any{"is_template": true}
+	res := githubRequest(http.MethodPatch, fmt.Sprintf("/repos/%s/%s", owner, repo), body, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to mark repository as template", res)
+	}
+}
+
+// readRuleset reads a repository ruleset as the rulesets endpoint takes
+// it, which needs at least a name and an enforcement.
+func readRuleset(path string) map[string]any {
+	data, err := os.ReadFile(path)
+	iferr("Failed to read ruleset: %v\n", err)
+
+	ruleset := map[string]any{}
+	err = json.Unmarshal([]byte(stripBom(string(data))), &ruleset)
+	iferr("Failed to parse ruleset: %v\n", err)
+
+	for _, field := range []string{"name", "enforcement"} {
+		if v, ok := ruleset[field].(string); !ok || v == "" {
+			output.error("Ruleset %s is missing %s\n", path, field)
+			os.Exit(1)
+		}
+	}
+
+	return ruleset
+}
+
+func createRuleset(owner string, repo string, ruleset map[string]any, config *appConfig) {
+	res := githubRequest(http.MethodPost, fmt.Sprintf("/repos/%s/%s/rulesets", owner, repo), ruleset, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		exitWithResponse("Failed to create ruleset", res)
+	}
+}
+
+func repoExists(owner string, repo string, config *appConfig) bool {
+	res := githubRequest(http.MethodGet, fmt.Sprintf("/repos/%s/%s", owner, repo), nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false
+	}
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to look up repository", res)
+	}
+	return true
+}
+
+// maxNameSuffix is the highest number --auto-suffix appends to a taken name.
+const maxNameSuffix = 20
+
+// availableName returns name, or the first of name-2, name-3 and so on up to
+// name-<maxNameSuffix> that is not taken under owner.
+func availableName(owner string, name string, config *appConfig) string {
+	if !repoExists(owner, name, config) {
+		return name
+	}
+
+	for n := 2; n <= maxNameSuffix; n++ {
+		candidate := fmt.Sprintf("%s-%d", name, n)
+		if !repoExists(owner, candidate, config) {
+			output.step("Repository %s/%s is taken, using %s\n", owner, name, candidate)
+			return candidate
+		}
+	}
+
+	output.error("Repository %s/%s is taken and so are %s-2 to %s-%d\n", owner, name, name, name, maxNameSuffix)
+	os.Exit(1)
+	return ""
+}
+
+// repoCreatedWithin reports whether owner/repo exists and was created no
+// longer than d ago, i.e. most likely by an earlier attempt of this run.
+func repoCreatedWithin(owner string, repo string, d time.Duration, config *appConfig) bool {
+	res := githubRequest(http.MethodGet, fmt.Sprintf("/repos/%s/%s", owner, repo), nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false
+	}
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to look up repository", res)
+	}
+
+	existing := struct {
+		CreatedAt time.Time `json:"created_at"`
+	}{}
+	decodeResponse(res, &existing)
+
+	return time.Since(existing.CreatedAt) <= d
+}
@@ -0,0 +1,568 @@
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActionsSecrets(t *testing.T) {
+	env := newTestEnv(t)
+
+	repoKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.api.handle(
+		"GET /repos/" + testUser + "/sealed/actions/secrets/public-key",
+		http.StatusOK,
+		fmt.Sprintf(`{"key_id": "568250167242549743", "key": %q}`, base64.StdEncoding.EncodeToString(repoKey.PublicKey().Bytes())),
+	)
+	env.api.handle("PUT /repos/" + testUser + "/sealed/actions/secrets/{name}", http.StatusCreated, "")
+
+	out := mustRun(t, "", "", "--secret", "DEPLOY_TOKEN=s3cr3t", "--secret", "EMPTY=", "sealed")
+
+	puts := env.api.received("PUT", "/repos/" + testUser + "/sealed/actions/secrets/.*")
+	if len(puts) != 2 {
+		t.Fatalf("got %d secret requests, want 2", len(puts))
+	}
+
+	want := map[string]string{"DEPLOY_TOKEN": "s3cr3t", "EMPTY": ""}
+	for _, put := range puts {
+		name := put.Path[strings.LastIndex(put.Path, "/") + 1:]
+		body := put.json(t)
+		if body["key_id"] != "568250167242549743" {
+			t.Errorf("%s: key_id = %v", name, body["key_id"])
+		}
+
+		box, err := base64.StdEncoding.DecodeString(body["encrypted_value"].(string))
+		if err != nil {
+			t.Fatalf("%s: encrypted_value: %v", name, err)
+		}
+		if got := string(openBox(t, box, repoKey)); got != want[name] {
+			t.Errorf("%s decrypts to %q, want %q", name, got, want[name])
+		}
+		if strings.Contains(string(put.Body), "s3cr3t") {
+			t.Errorf("%s: secret sent in the clear", name)
+		}
+	}
+
+	if strings.Contains(out.stdout + out.stderr, "s3cr3t") {
+		t.Errorf("secret printed")
+	}
+}
+
+func TestActionsSecretsInPlan(t *testing.T) {
+	newTestEnv(t)
+	out := mustRun(t, "", "", "--dry-run", "--secret", "DEPLOY_TOKEN=s3cr3t", "sealed")
+
+	if !strings.Contains(out.stdout, "set actions secret DEPLOY_TOKEN") {
+		t.Errorf("plan does not list the secret:\n%s", out.stdout)
+	}
+	if strings.Contains(out.stdout + out.stderr, "s3cr3t") {
+		t.Errorf("plan shows the secret value")
+	}
+}
+
+func TestActionsVariables(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /repos/" + testUser + "/plain/actions/variables", http.StatusCreated, "")
+	mustRun(t, "", "", "--variable", "REGION=eu-west-1", "plain")
+
+	posts := env.api.received("POST", "/repos/" + testUser + "/plain/actions/variables")
+	if len(posts) != 1 {
+		t.Fatalf("got %d variable requests, want 1", len(posts))
+	}
+	if body := posts[0].json(t); body["name"] != "REGION" || body["value"] != "eu-west-1" {
+		t.Errorf("variable body = %v", body)
+	}
+}
+
+func TestOwnerTypeEndpoint(t *testing.T) {
+	tests := []struct {
+		args []string
+		userType string
+		wantDetect bool
+		wantCreate string
+		wantErr string
+	}{
+		{[]string{"--owner", "acme", "app"}, "Organization", true, "/orgs/acme/repos", ""},
+		{[]string{"--owner", "acme", "--owner-type", "org", "app"}, "", false, "/orgs/acme/repos", ""},
+		{[]string{"--owner", testUser, "app"}, "", false, "/user/repos", ""},
+		{[]string{"--owner", "someone", "app"}, "User", true, "", "Cannot create repository for another user: someone"},
+		{[]string{"--owner", "nobody", "app"}, "", true, "", "Unknown owner: nobody"},
+	}
+
+	for _, tt := range tests {
+		env := newTestEnv(t)
+		if tt.userType != "" {
+			env.api.handle("GET /users/{owner}", http.StatusOK, fmt.Sprintf(`{"type": %q}`, tt.userType))
+		}
+
+		out := runMain(t, "", "", tt.args...)
+
+		if detected := len(env.api.received("GET", "/users/.*")) > 0; detected != tt.wantDetect {
+			t.Errorf("%q: owner type looked up: %v, want %v", tt.args, detected, tt.wantDetect)
+		}
+		creates := env.api.received("POST", ".*")
+		if tt.wantErr != "" {
+			if out.code != 1 || !strings.Contains(out.stderr, tt.wantErr) || len(creates) > 0 {
+				t.Errorf("%q: exit %d, %d creates, stderr %q", tt.args, out.code, len(creates), out.stderr)
+			}
+			continue
+		}
+		if out.code != 0 {
+			t.Fatalf("%q: exit %d:\n%s", tt.args, out.code, out.stderr)
+		}
+		if len(creates) != 1 || creates[0].Path != tt.wantCreate {
+			t.Errorf("%q: created through %v, want %s", tt.args, creates, tt.wantCreate)
+		}
+	}
+}
+
+func TestPruneDefaultLabels(t *testing.T) {
+	env := newTestEnv(t)
+	labels := "/repos/" + testUser + "/clean/labels"
+	env.api.handle("GET " + labels, http.StatusOK, `[{"name": "bug"}, {"name": "good first issue"}]`)
+	env.api.handle("DELETE " + labels + "/{name}", http.StatusNoContent, "")
+
+	mustRun(t, "", "", "--prune-default-labels", "clean")
+
+	if got := env.api.received("GET", "^" + labels + `\?per_page=100$`); len(got) != 1 {
+		t.Errorf("labels listed %d times, want once", len(got))
+	}
+	deletes := env.api.received("DELETE", ".*")
+	paths := []string{}
+	for _, r := range deletes {
+		paths = append(paths, r.Path)
+	}
+	want := []string{labels + "/bug", labels + "/good%20first%20issue"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("deleted %q, want %q", paths, want)
+	}
+}
+
+func TestPruneDefaultLabelsFailure(t *testing.T) {
+	env := newTestEnv(t)
+	labels := "/repos/" + testUser + "/clean/labels"
+	env.api.handle("GET " + labels, http.StatusOK, `[{"name": "bug"}]`)
+	env.api.handle("DELETE " + labels + "/{name}", http.StatusForbidden, `{"message": "Forbidden"}`)
+
+	out := runMain(t, "", "", "--prune-default-labels", "clean")
+	if out.code != 1 || !strings.Contains(out.stderr, "Failed to delete label bug: 403 Forbidden") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestSinceReusesRecentRepo(t *testing.T) {
+	tests := []struct {
+		createdAgo time.Duration
+		wantCreate bool
+	}{
+		{time.Minute, false},
+		{2 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		env := newTestEnv(t)
+		env.initBare(t, testUser, "retried")
+		created := time.Now().Add(-tt.createdAgo).UTC().Format(time.RFC3339)
+		env.api.handle("GET /repos/" + testUser + "/retried", http.StatusOK, fmt.Sprintf(`{"created_at": %q}`, created))
+
+		out := runMain(t, "", "", "--since", "1h", "retried")
+
+		creates := env.api.received("POST", "^/user/repos$")
+		if created := len(creates) > 0; created != tt.wantCreate {
+			t.Errorf("created %v ago: create requested %v, want %v", tt.createdAgo, created, tt.wantCreate)
+		}
+		if tt.wantCreate {
+			continue
+		}
+		if out.code != 0 || !strings.Contains(out.stdout, "Repository " + testUser + "/retried was created within 1h0m0s, reusing it") {
+			t.Errorf("exit %d, output:\n%s%s", out.code, out.stdout, out.stderr)
+		}
+		if got := git(t, env.projPath("retried"), "log", "--format=%s"); got != "initial commit" {
+			t.Errorf("reused repository not set up: %q", got)
+		}
+	}
+}
+
+func TestOwnerFallbackOnOrg404(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /orgs/acme/repos", http.StatusNotFound, `{"message": "Not Found"}`)
+
+	out := mustRun(t, "", "", "--owner", "acme", "--owner-type", "org", "--owner-fallback", "app")
+
+	if !strings.Contains(out.stderr, "Warning: cannot create repository under acme, organization responded with 404 Not Found, creating it under " + testUser) {
+		t.Errorf("no fallback warning:\n%s", out.stderr)
+	}
+	if got := len(env.api.received("POST", "^/orgs/acme/repos$")); got != 1 {
+		t.Errorf("org create requested %d times, want 1", got)
+	}
+	if got := len(env.api.received("POST", "^/user/repos$")); got != 1 {
+		t.Errorf("user create requested %d times, want 1", got)
+	}
+	if url := git(t, env.projPath("app"), "config", "remote.origin.url"); url != "git@github.com:" + testUser + "/app.git" {
+		t.Errorf("origin = %q", url)
+	}
+}
+
+func TestNoOwnerFallbackOnOrg404(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /orgs/acme/repos", http.StatusNotFound, `{"message": "Not Found"}`)
+
+	out := runMain(t, "", "", "--owner", "acme", "--owner-type", "org", "app")
+	if out.code != 1 {
+		t.Errorf("exit %d, want 1 without --owner-fallback", out.code)
+	}
+	if got := len(env.api.received("POST", "^/user/repos$")); got != 0 {
+		t.Errorf("fell back to the user without --owner-fallback")
+	}
+}
+
+func TestAsTemplate(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("PATCH /repos/" + testUser + "/base", http.StatusOK, `{"is_template": true}`)
+
+	mustRun(t, "", "", "--as-template", "base")
+
+	patches := env.api.received("PATCH", ".*")
+	if len(patches) != 1 || patches[0].Path != "/repos/" + testUser + "/base" {
+		t.Fatalf("patches = %v", patches)
+	}
+	if body := patches[0].json(t); body["is_template"] != true || len(body) != 1 {
+		t.Errorf("patch body = %v", body)
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /repos/" + testUser + "/taken", http.StatusOK, `{"name": "taken"}`)
+
+	out := runMain(t, "", "", "--check-name", "free")
+	if out.code != 0 || !strings.HasSuffix(out.stdout, "\n" + testUser + "/free is available\n") {
+		t.Errorf("available: exit %d, stdout %q", out.code, out.stdout)
+	}
+
+	out = runMain(t, "", "", "--check-name", "taken")
+	if out.code != 2 || !strings.HasSuffix(out.stdout, "\n" + testUser + "/taken is taken\n") {
+		t.Errorf("taken: exit %d, stdout %q", out.code, out.stdout)
+	}
+
+	lookups := env.api.received("GET", "/repos/.*")
+	if len(lookups) != 2 || lookups[0].Header.Get("Authorization") != "token " + testToken {
+		t.Errorf("lookups = %v", lookups)
+	}
+	if got := len(env.api.received("POST", ".*")); got != 0 {
+		t.Errorf("--check-name created %d repositories", got)
+	}
+}
+
+func TestCreateEndpointOverride(t *testing.T) {
+	env := newTestEnv(t, "create_endpoint = /api/v1/orgs/{owner}/repos")
+	env.api.handleFunc("POST /api/v1/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		env.createRepo(t, w, r, "acme")
+	})
+
+	mustRun(t, "", "", "--owner", "acme", "--owner-type", "org", "forged")
+
+	if got := len(env.api.received("POST", "/api/v1/orgs/acme/repos")); got != 1 {
+		t.Errorf("create requested %d times at the overridden path", got)
+	}
+	if got := len(env.api.received("POST", "/orgs/acme/repos")); got != 0 {
+		t.Errorf("create requested at the default path")
+	}
+}
+
+func TestInvalidCreateEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"api/v1/repos", "https://evil.example/repos", "/a/../repos", "/repos?x=1"} {
+		newTestEnv(t, "create_endpoint = " + endpoint)
+		out := runMain(t, "", "", "forged")
+		if out.code != 1 || !strings.Contains(out.stderr, "Invalid create_endpoint, expected absolute path: " + endpoint) {
+			t.Errorf("%s: exit %d, stderr %q", endpoint, out.code, out.stderr)
+		}
+	}
+}
+
+func TestRuleset(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /repos/" + testUser + "/ruled/rulesets", http.StatusCreated, `{"id": 1}`)
+	ruleset := `{
+		"name": "main",
+		"target": "branch",
+		"enforcement": "active",
+		"conditions": {"ref_name": {"include": ["~DEFAULT_BRANCH"], "exclude": []}},
+		"rules": [{"type": "deletion"}, {"type": "non_fast_forward"}]
+	}`
+	rulesetPath := filepath.Join(t.TempDir(), "ruleset.json")
+	writeFile(t, rulesetPath, ruleset)
+
+	mustRun(t, "", "", "--ruleset", rulesetPath, "--allow-auto-merge", "true", "ruled")
+
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 || creates[0].json(t)["allow_auto_merge"] != true {
+		t.Errorf("create requests %v, want allow_auto_merge", creates)
+	}
+
+	posts := env.api.received("POST", "/repos/" + testUser + "/ruled/rulesets")
+	if len(posts) != 1 {
+		t.Fatalf("got %d ruleset requests, want 1", len(posts))
+	}
+	want := map[string]any{}
+	json.Unmarshal([]byte(ruleset), &want)
+	if got := posts[0].json(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("ruleset body %v, want %v", got, want)
+	}
+}
+
+func TestInvalidRuleset(t *testing.T) {
+	tests := []struct {
+		content string
+		want string
+	}{
+		{`{"name": "main"`, "Failed to parse ruleset"},
+		{`{"name": "main"}`, "is missing enforcement"},
+		{`{"enforcement": "active"}`, "is missing name"},
+	}
+
+	for _, tt := range tests {
+		env := newTestEnv(t)
+		rulesetPath := filepath.Join(t.TempDir(), "ruleset.json")
+		writeFile(t, rulesetPath, tt.content)
+
+		out := runMain(t, "", "", "--ruleset", rulesetPath, "ruled")
+		if out.code != 1 || !strings.Contains(out.stderr, tt.want) {
+			t.Errorf("%s: exit %d, stderr %q", tt.content, out.code, out.stderr)
+		}
+		if got := len(env.api.received("POST", ".*")); got != 0 {
+			t.Errorf("%s: repository created with an invalid ruleset", tt.content)
+		}
+	}
+}
+
+func TestGithubInitCombined(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handleFunc("POST /orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		// GitHub commits README.md, .gitignore and LICENSE itself.
+		bare := env.initBare(t, "acme", "inited")
+		work := t.TempDir()
+		git(t, work, "init", "-q", "-b", "main")
+		for _, f := range []string{"README.md", ".gitignore", "LICENSE"} {
+			writeFile(t, filepath.Join(work, f), "by github\n")
+		}
+		git(t, work, "add", ".")
+		git(t, work, "commit", "-q", "-m", "Initial commit")
+		git(t, work, "push", "-q", bare, "main")
+		git(t, bare, "symbolic-ref", "HEAD", "refs/heads/main")
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name": "inited", "full_name": "acme/inited"}`)
+	})
+
+	out := mustRun(t, "", "", "--owner", "acme", "--owner-type", "org", "--github-init", "--gitignore-template", "Go", "--license", "MIT", "inited")
+
+	creates := env.api.received("POST", ".*")
+	if len(creates) != 1 {
+		t.Fatalf("got %d create requests, want 1", len(creates))
+	}
+	body := creates[0].json(t)
+	if body["auto_init"] != true || body["gitignore_template"] != "Go" || body["license_template"] != "mit" {
+		t.Errorf("create body = %v", body)
+	}
+	if got := len(env.api.received("GET", ".*")); got != 0 {
+		t.Errorf("%d templates fetched, GitHub renders them", got)
+	}
+
+	projPath := env.projPath("inited")
+	if got := git(t, projPath, "log", "--format=%s"); got != "Initial commit" {
+		t.Errorf("local commits on top of GitHub's: %q", got)
+	}
+	if got := readFile(t, filepath.Join(projPath, "README.md")); got != "by github\n" {
+		t.Errorf("README.md rewritten locally: %q", got)
+	}
+	if !strings.Contains(out.stdout, "Nothing to commit, repository was initialized by GitHub") {
+		t.Errorf("local steps not skipped:\n%s", out.stdout)
+	}
+}
+
+// handleTokenedCreate creates acme repositories only for requests made with
+// token.
+func (env *testEnv) handleTokenedCreate(t *testing.T, token string) {
+	env.api.handleFunc("POST /orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token " + token {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message": "Bad credentials"}`)
+			return
+		}
+		env.createRepo(t, w, r, "acme")
+	})
+}
+
+func TestRetryAuth(t *testing.T) {
+	env := newTestEnv(t)
+	env.handleTokenedCreate(t, "new-token")
+
+	out := mustRun(t, "", "y\nnew-token\ny\n", "--owner", "acme", "--owner-type", "org", "--retry-auth", "authed")
+	if !strings.Contains(out.stdout, "GitHub rejected the configured token, paste a new one:\n") {
+		t.Errorf("no token prompt:\n%s", out.stdout)
+	}
+	if creates := env.api.received("POST", "/orgs/acme/repos"); len(creates) != 2 {
+		t.Errorf("%d create requests, want the rejected one and its retry", len(creates))
+	}
+	if got := git(t, env.projPath("authed"), "log", "--oneline"); got == "" {
+		t.Errorf("nothing committed after the retry")
+	}
+	if config := readFile(t, env.configPath); !strings.Contains(config, "gh_apikey = new-token\n") || strings.Contains(config, testToken) {
+		t.Errorf("new token not saved:\n%s", config)
+	}
+}
+
+func TestNoRetryAuth(t *testing.T) {
+	env := newTestEnv(t)
+	env.handleTokenedCreate(t, "new-token")
+
+	out := runMain(t, "", "", "--owner", "acme", "--owner-type", "org", "noauth")
+	if out.code != 1 || !strings.Contains(out.stderr, "Failed to create repository\n") {
+		t.Errorf("exit %d, stderr:\n%s", out.code, out.stderr)
+	}
+	if creates := env.api.received("POST", "/orgs/acme/repos"); len(creates) != 1 {
+		t.Errorf("%d create requests, want no retry", len(creates))
+	}
+}
+
+func TestHostSelection(t *testing.T) {
+	gitea := newMockApi(t)
+	env := newTestEnv(t,
+		"gitea_apikey = gitea-token",
+		"gitea_api_url = " + gitea.URL + "/api/v1/",
+		"gitea_clone_host = gitea.example.com",
+	)
+	gitea.handleFunc("POST /api/v1/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		env.createRepo(t, w, r, testUser)
+	})
+	gitea.handle("POST /hook", http.StatusNoContent, "")
+	writeFile(t, os.Getenv("GIT_CONFIG_GLOBAL"), readFile(t, os.Getenv("GIT_CONFIG_GLOBAL")) +
+		fmt.Sprintf("[url %q]\n\tinsteadOf = git@gitea.example.com:\n", env.remotes + "/"))
+
+	mustRun(t, "", "", "--host", "gitea", "--notify-webhook", gitea.URL + "/hook", "on-gitea")
+
+	creates := gitea.received("POST", "/api/v1/user/repos")
+	if len(creates) != 1 || creates[0].Header.Get("Authorization") != "token gitea-token" {
+		t.Errorf("gitea create requests %v, want one with the gitea token", creates)
+	}
+	if creates := env.api.received("POST", "/user/repos"); len(creates) > 0 {
+		t.Errorf("github api used with --host gitea")
+	}
+	if got := git(t, env.projPath("on-gitea"), "config", "remote.origin.url"); got != "git@gitea.example.com:" + testUser + "/on-gitea.git" {
+		t.Errorf("origin %q, want the gitea clone host", got)
+	}
+	hooks := gitea.received("POST", "/hook")
+	if len(hooks) != 1 || hooks[0].json(t)["repo_url"] != "https://gitea.example.com/" + testUser + "/on-gitea" {
+		t.Errorf("webhook requests %v, want the gitea repo url", hooks)
+	}
+
+	mustRun(t, "", "", "on-github")
+	creates = env.api.received("POST", "/user/repos")
+	if len(creates) != 1 || creates[0].Header.Get("Authorization") != "token " + testToken {
+		t.Errorf("github create requests %v, want one with the github token", creates)
+	}
+	if got := git(t, env.projPath("on-github"), "config", "remote.origin.url"); got != "git@github.com:" + testUser + "/on-github.git" {
+		t.Errorf("origin %q, want github.com", got)
+	}
+}
+
+func TestGitHost(t *testing.T) {
+	tests := []struct {
+		config appConfig
+		want string
+	}{
+		{appConfig{host: "github"}, "github.com"},
+		{appConfig{host: "github", apiBaseUrl: "https://ghe.example.com/api/v3", cloneHost: "ghe.example.com"}, "ghe.example.com"},
+		{appConfig{host: "gitea", apiBaseUrl: "https://git.example.org/api/v1"}, "git.example.org"},
+		{appConfig{host: "gitea", apiBaseUrl: "https://git.example.org:3000/api/v1", cloneHost: "ssh.example.org"}, "ssh.example.org"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.config.gitHost(); got != tt.want {
+			t.Errorf("gitHost() of %+v = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestGiteaRequiresApiUrl(t *testing.T) {
+	newTestEnv(t, "gitea_apikey = gitea-token")
+
+	out := runMain(t, "", "", "--host", "gitea", "demo")
+	if out.code != 1 || !strings.HasSuffix(out.stderr, "Config is missing gitea_api_url\n") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestInvalidCloneHost(t *testing.T) {
+	newTestEnv(t, "clone_host = git@github.com:")
+
+	out := runMain(t, "", "", "demo")
+	if out.code != 1 || !strings.HasSuffix(out.stderr, "Invalid clone_host, expected host name like github.com: git@github.com:\n") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestAutoSuffix(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /repos/" + testUser + "/taken", http.StatusOK, `{"name": "taken"}`)
+
+	out := mustRun(t, "", "", "--auto-suffix", "taken")
+
+	if !strings.Contains(out.stdout, "Repository " + testUser + "/taken is taken, using taken-2\n") {
+		t.Errorf("suffix not reported:\n%s", out.stdout)
+	}
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 || creates[0].json(t)["name"] != "taken-2" {
+		t.Errorf("create requests %v, want one for taken-2", creates)
+	}
+	if got := git(t, env.projPath("taken-2"), "log", "--format=%s"); got != "initial commit" {
+		t.Errorf("taken-2 not created: %q", got)
+	}
+}
+
+func TestAutoSuffixExhausted(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /repos/" + testUser + "/{name}", http.StatusOK, `{}`)
+
+	out := runMain(t, "", "", "--auto-suffix", "popular")
+	want := fmt.Sprintf("Repository %s/popular is taken and so are popular-2 to popular-%d\n", testUser, maxNameSuffix)
+	if out.code != 1 || !strings.HasSuffix(out.stderr, want) {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestAutoSuffixSummary(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /repos/" + testUser + "/taken", http.StatusOK, `{"name": "taken"}`)
+
+	out := mustRun(t, "", "y\ny\n", "--auto-suffix", "taken", "free")
+
+	rows := []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^taken-2 +ok +https://github\.com/octocat/taken-2 +$`),
+		regexp.MustCompile(`(?m)^free +ok +https://github\.com/octocat/free +$`),
+	}
+	for _, row := range rows {
+		if !row.MatchString(out.stdout) {
+			t.Errorf("no summary row matching %s:\n%s", row, out.stdout)
+		}
+	}
+	if _, err := os.Stat(env.projPath("taken-2")); err != nil {
+		t.Errorf("taken-2 not created: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// commitGroup is a set of generated files --grouped-commits commits on
+// their own, with a conventional commit type and scope.
+type commitGroup struct {
+	kind string
+	scope string
+	summary string
+	paths []string
+}
+
+var commitGroups = []commitGroup{
+	{"docs", "readme", "add project documentation", []string{"README.md", "CHANGELOG.md", "AUTHORS", ".mailmap"}},
+	{"chore", "license", "add license", []string{"LICENSE", "NOTICE"}},
+	{"ci", "github", "add ci configuration", []string{".github", ".githooks"}},
+}
+
+// commitGrouped commits the files of each commit group present in projPath,
+// leaving everything else for the initial commit. It reports whether any
+// group was committed.
+func commitGrouped(projPath string, opts *appOptions) bool {
+	committed := false
+	for _, g := range commitGroups {
+		paths := []string{}
+		for _, p := range g.paths {
+			if _, err := os.Stat(filepath.Join(projPath, p)); err == nil {
+				paths = append(paths, p)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		cmd := exec.CommandContext(runCtx, "/bin/git", append([]string{"add", "--"}, paths...)...)
+		cmd.Dir = projPath
+		cmd.Stderr = output.raw()
+		err := cmd.Run()
+		iferr("Failed to add changes: %v\n", err)
+
+		if !hasStagedChanges(projPath) {
+			continue
+		}
+		commitStaged(projPath, fmt.Sprintf("%s(%s): %s", g.kind, g.scope, g.summary), opts)
+		committed = true
+	}
+
+	return committed
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupedCommits(t *testing.T) {
+	env := newTestEnv(t)
+	handleLicenses(env.api)
+	mustRun(t, "", "", "--grouped-commits", "--license", "MIT", "--authors", "--git-hooks", "--co-author", "Ada <ada@example.com>", "grouped")
+	projPath := env.projPath("grouped")
+
+	want := "docs(readme): add project documentation\n" +
+		"chore(license): add license\n" +
+		"ci(github): add ci configuration\n" +
+		"chore: initial commit"
+	if got := git(t, projPath, "log", "--reverse", "--format=%s"); got != want {
+		t.Errorf("commits:\n%s\nwant:\n%s", got, want)
+	}
+
+	files := map[string]string{
+		"HEAD~3": "AUTHORS\nREADME.md",
+		"HEAD~2": "LICENSE",
+		"HEAD~1": ".githooks/pre-commit",
+		"HEAD": ".gitignore",
+	}
+	for rev, want := range files {
+		if got := git(t, projPath, "show", "--format=", "--name-only", rev); got != want {
+			t.Errorf("%s commits %q, want %q", rev, got, want)
+		}
+	}
+
+	trailers := git(t, projPath, "log", "--format=%(trailers:key=Co-authored-by,valueonly)")
+	if want := "Ada <ada@example.com>\n\n"; trailers != strings.Repeat(want, 3) + "Ada <ada@example.com>" {
+		t.Errorf("co-author trailers: %q", trailers)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// templateIgnoreName is the file a template repository may ship to keep
+// some of its files, like its own README or LICENSE, out of the project.
+// It uses gitignore syntax and is not copied into the project.
+const templateIgnoreName = ".templateignore"
+
+type ignorePattern struct {
+	re *regexp.Regexp
+	negate bool
+	dirOnly bool
+}
+
+// templateIgnore holds the patterns of a .templateignore in file order, the
+// last one matching a path deciding whether it is ignored.
+type templateIgnore []ignorePattern
+
+func loadTemplateIgnore(dir string) templateIgnore {
+	ignore, err := readTemplateIgnore(dir)
+	if err != nil {
+		output.error("%v\n", err)
+		os.Exit(1)
+	}
+	return ignore
+}
+
+// readTemplateIgnore is loadTemplateIgnore for callers that report problems
+// instead of exiting on them.
+func readTemplateIgnore(dir string) (templateIgnore, error) {
+	data, err := os.ReadFile(filepath.Join(dir, templateIgnoreName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read template ignore file: %v", err)
+	}
+
+	ignore := templateIgnore{}
+	for _, line := range strings.Split(stripBom(string(data)), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := ignorePattern{}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, "\\")
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		// A slash anywhere but at the end anchors the pattern to the
+		// template root, otherwise it matches at any depth.
+		prefix := "^(.*/)?"
+		if strings.Contains(line, "/") {
+			prefix = "^"
+			line = strings.TrimPrefix(line, "/")
+		}
+
+		re, err := regexp.Compile(prefix + globToRegexp(line) + "$")
+		if err != nil {
+			return nil, fmt.Errorf("Invalid template ignore pattern %s: %v", line, err)
+		}
+		p.re = re
+		ignore = append(ignore, p)
+	}
+
+	return ignore, nil
+}
+
+// globToRegexp translates a gitignore glob, where * and ? stop at a slash
+// and ** crosses any number of dirs.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i + 1:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := glob[i + 1:i + 1 + end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i + 1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
+
+// ignores reports whether the file or dir at rel, relative to the template
+// root, is left out of the copy.
+func (t templateIgnore) ignores(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	ignored := false
+
+	for _, p := range t {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			ignored = !p.negate
+		}
+	}
+
+	return ignored
+}
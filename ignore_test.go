@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateIgnorePatterns(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, templateIgnoreName), "\ufeff# template docs\nTEMPLATE.md\n/LICENSE\n*.log\n!keep.log\nbuild/\ndocs/**/draft-?.md\n")
+	ignore, err := readTemplateIgnore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel string
+		isDir bool
+		want bool
+	}{
+		{"TEMPLATE.md", false, true},
+		{"sub/TEMPLATE.md", false, true},
+		{"LICENSE", false, true},
+		{"sub/LICENSE", false, false},
+		{"debug.log", false, true},
+		{"logs/debug.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"docs/draft-1.md", false, true},
+		{"docs/a/b/draft-2.md", false, true},
+		{"docs/draft-10.md", false, false},
+		{"main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := ignore.ignores(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("ignores(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateIgnoreCopy(t *testing.T) {
+	env := newTestEnv(t)
+	template := initTemplateRepo(t, map[string]string{
+		templateIgnoreName: "TEMPLATE.md\nLICENSE\nbuild/\n",
+		"TEMPLATE.md": "# how to use this template\n",
+		"LICENSE": "template license\n",
+		"build/out.bin": "binary\n",
+		"main.go": "package main\n",
+	})
+
+	mustRun(t, "", "", "--template-git", template, "ignoring")
+
+	projPath := env.projPath("ignoring")
+	for _, name := range []string{templateIgnoreName, "TEMPLATE.md", "LICENSE", "build"} {
+		if _, err := os.Lstat(filepath.Join(projPath, name)); !os.IsNotExist(err) {
+			t.Errorf("%s copied into the project", name)
+		}
+	}
+	if got := readFile(t, filepath.Join(projPath, "main.go")); got != "package main\n" {
+		t.Errorf("main.go %q, want it copied", got)
+	}
+}
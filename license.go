@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type githubLicense struct {
+	Key string `json:"key"`
+	Name string `json:"name"`
+	SpdxId string `json:"spdx_id"`
+	Body string `json:"body"`
+}
+
+func fetchLicense(key string, config *appConfig) githubLicense {
+	res := githubRequest(http.MethodGet, "/licenses/" + strings.ToLower(key), nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		output.error("Unknown license: %s\n", key)
+		os.Exit(1)
+	}
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to fetch license", res)
+	}
+
+	license := githubLicense{}
+	decodeResponse(res, &license)
+	return license
+}
+
+// usesNotice reports whether license conventionally ships with a NOTICE file.
+func usesNotice(license string) bool {
+	return strings.EqualFold(license, "apache-2.0")
+}
+
+func createLicense(projPath string, license githubLicense, holder string) {
+	year := strconv.Itoa(time.Now().Year())
+	text := strings.NewReplacer("[year]", year, "[fullname]", holder).Replace(license.Body)
+
+	f := createFile(filepath.Join(projPath, "LICENSE"))
+	f.WriteString(text)
+	f.Close()
+}
+
+// appendReadmeLicense adds a License section naming the license and linking
+// the LICENSE file to the end of README.md.
+func appendReadmeLicense(projPath string, license githubLicense) {
+	readmePath := filepath.Join(projPath, "README.md")
+
+	data, err := os.ReadFile(readmePath)
+	iferr("Failed to read README.md: %v\n", err)
+
+	readme := strings.TrimRight(string(data), "\r\n")
+	readme += fmt.Sprintf("\n\n## License\n\nThis project is licensed under the [%s](LICENSE).\n", license.Name)
+
+	err = os.WriteFile(readmePath, fileStyle.apply([]byte(readme)), 0644)
+	iferr("Failed to write README.md: %v\n", err)
+}
+
+func createNotice(projName string, projPath string, holder string) {
+	f := createFile(filepath.Join(projPath, "NOTICE"))
+	fmt.Fprintf(f, "%s\nCopyright %d %s\n", buildTitle(projName), time.Now().Year(), holder)
+	f.Close()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// handleLicenses serves apache-2.0 and mit from the mock api.
+func handleLicenses(api *mockApi) {
+	api.handle("GET /licenses/apache-2.0", http.StatusOK,
+		`{"key": "apache-2.0", "name": "Apache License 2.0", "spdx_id": "Apache-2.0", "body": "Copyright [year] [fullname]\n"}`)
+	api.handle("GET /licenses/mit", http.StatusOK,
+		`{"key": "mit", "name": "MIT License", "spdx_id": "MIT", "body": "Copyright (c) [year] [fullname]\n"}`)
+}
+
+func TestNotice(t *testing.T) {
+	env := newTestEnv(t)
+	handleLicenses(env.api)
+	mustRun(t, "", "", "--license", "Apache-2.0", "--notice", "my-tool")
+
+	want := fmt.Sprintf("My Tool\nCopyright %d %s\n", time.Now().Year(), testUser)
+	if notice := readFile(t, filepath.Join(env.projPath("my-tool"), "NOTICE")); notice != want {
+		t.Errorf("NOTICE = %q, want %q", notice, want)
+	}
+	if got := git(t, env.projPath("my-tool"), "ls-files", "NOTICE"); got != "NOTICE" {
+		t.Errorf("NOTICE not committed")
+	}
+}
+
+func TestNoticeRequiresApache(t *testing.T) {
+	newTestEnv(t)
+	out := runMain(t, "", "", "--license", "MIT", "--notice", "my-tool")
+	if out.code != 1 || !strings.Contains(out.stderr, "--notice requires --license Apache-2.0") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestReadmeLicenseSection(t *testing.T) {
+	env := newTestEnv(t)
+	handleLicenses(env.api)
+	mustRun(t, "", "", "--license", "MIT", "--readme-license-section", "licensed")
+
+	readme := readFile(t, filepath.Join(env.projPath("licensed"), "README.md"))
+	if !strings.HasSuffix(readme, "\n\n## License\n\nThis project is licensed under the [MIT License](LICENSE).\n") {
+		t.Errorf("README.md has no license section:\n%s", readme)
+	}
+	if got := git(t, env.projPath("licensed"), "show", "HEAD:README.md"); !strings.Contains(got, "## License") {
+		t.Errorf("license section not committed")
+	}
+}
+
+func TestReadmeLicenseSectionRequiresLicense(t *testing.T) {
+	newTestEnv(t)
+	out := runMain(t, "", "", "--readme-license-section", "licensed")
+	if out.code != 1 || !strings.Contains(out.stderr, "--readme-license-section requires --license") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
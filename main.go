@@ -7,252 +7,2635 @@ import (
 	"strings"
 	"bufio"
 	"net/http"
-	"io"
-	"bytes"
 	"encoding/json"
-	"path"
+	"path/filepath"
+	"strconv"
+	"slices"
+	"time"
+	"regexp"
+	"context"
+	"maps"
+	"io"
+	"unicode"
 )
 
 type appConfig struct {
 	ghUsername string
 	ghApiKey string
+	host string
+	apiKeys map[string]string
+	apiUrls map[string]string
+	cloneHosts map[string]string
+	cloneHost string
 	projDir string
+	mergeSettings map[string]bool
+	retry retryBudget
+	defaultTemplates []string
+	defaultDirs []string
+	confirmDefault bool
+	gitignoreHeader bool
+	apiBaseUrl string
+	createEndpoint string
+	trace bool
+	editor string
+	tlsMinVersion uint16
+	readOnly bool
+	textStyle textStyle
+}
+
+type appOptions struct {
+	projName string
+	projNames []string
+	flagArgs []string
+	mergeSettings map[string]bool
+	templates []string
+	dirs []string
+	modulePath string
+	gitHooks bool
+	apiFields map[string]any
+	dirTransforms []string
+	notifyCommand string
+	notifyWebhook string
+	license string
+	notice bool
+	githubInit bool
+	gitignoreTemplate string
+	maxRetries int
+	maxRetriesTotal int
+	retryOn []string
+	retryJitter bool
+	tlsMinVersion uint16
+	noGitignoreHeader bool
+	branch string
+	reinitExisting bool
+	clean bool
+	checkName bool
+	autoSuffix bool
+	printCloneCommand bool
+	variables [][2]string
+	secrets [][2]string
+	signoff bool
+	fixIdentity bool
+	groupedCommits bool
+	deleteOnEmptyPush bool
+	localFirst bool
+	templateGit string
+	templateUrl string
+	vars map[string]string
+	envFile string
+	owner string
+	ownerType string
+	planFormat string
+	dryRun bool
+	emitScript string
+	validateRemote bool
+	fork string
+	contribute string
+	base string
+	description string
+	title string
+	descriptionMaxLen int
+	deployKey string
+	deployKeyWrite bool
+	descriptionFromGit bool
+	noNetwork bool
+	prefetchTemplates bool
+	withTests bool
+	format bool
+	vscode bool
+	makefile bool
+	pruneLabels bool
+	asTemplate bool
+	ruleset string
+	rulesetBody map[string]any
+	mailmap bool
+	verifySsh bool
+	metrics bool
+	summaryOnly bool
+	json bool
+	color string
+	logFile string
+	edit bool
+	trace bool
+	since time.Duration
+	host string
+	timeout time.Duration
+	here bool
+	dirPermissionsCheck bool
+	authors bool
+	changelog bool
+	versionFile string
+	funding map[string][]string
+	readmeLicenseSection bool
+	noDefaultFiles bool
+	ownerFallback bool
+	retryAuth bool
+	addPatterns []string
+	coAuthors []string
+}
+
+// templateSource is the custom template to copy into the project, set by
+// either --template-git or --template-url.
+func (opts *appOptions) templateSource() string {
+	if opts.templateUrl != "" {
+		return opts.templateUrl
+	}
+	return opts.templateGit
+}
+
+const genericPreCommitHook = `#!/bin/sh
+exec git diff --cached --check
+`
+
+const goPreCommitHook = `#!/bin/sh
+files=$(git diff --cached --name-only --diff-filter=ACM -- '*.go')
+[ -z "$files" ] && exit 0
+
+unformatted=$(gofmt -l $files)
+if [ -n "$unformatted" ]; then
+	echo "The following files need gofmt:"
+	echo "$unformatted"
+	exit 1
+fi
+`
+
+// apiKeyFields holds the config field each supported host reads its token
+// from, so one config can hold tokens for several forges.
+var apiKeyFields = map[string]string{
+	"github": "gh_apikey",
+	"gitea": "gitea_apikey",
+}
+
+// parseHost validates a host name given in the config or with --host.
+func parseHost(s string) string {
+	if _, ok := apiKeyFields[s]; !ok {
+		output.error("Unknown host, expected github or gitea: %s\n", s)
+		os.Exit(1)
+	}
+	return s
 }
 
-func printUsage(stream *os.File) {
+var mergeSettingFields = []string{
+	"allow_squash_merge",
+	"allow_merge_commit",
+	"allow_rebase_merge",
+	"delete_branch_on_merge",
+	"allow_auto_merge",
+}
+
+func printUsage(stream io.Writer) {
 	fmt.Fprintf(
 		stream,
-		"Usage: %s [NAME|OPTION]\n" +
+		"Usage: %s [OPTION]... NAME...\n" +
 		"Creates new programming project\n" +
 		"\n" +
 		"NAME:\n" +
-		"   project name in kebab-case\n" +
+		"   project name in kebab-case, several names create several projects\n" +
+		"   OWNER/NAME creates repository under OWNER like --owner\n" +
 		"\n" +
 		"OPTION:\n" +
-		"   --help       shows this message\n" +
-		"   --gen-config generates config file\n",
+		"   --help                         shows this message\n" +
+		"   --config PATH                  uses config file at PATH, also read from\n" +
+		"                                  CREATE_PROJECT_CONFIG\n" +
+		"   --name-from FILE               reads project name from FILE\n" +
+		"   --gen-config                   generates config file\n" +
+		"   --list                         lists previously created projects\n" +
+		"   --validate-template DIR        checks template directory for manifest errors\n" +
+		"                                  and placeholders without a variable\n" +
+		"   --preset NAME                  expands to options of [preset NAME] in config\n" +
+		"   --self-update                  updates to latest release binary\n" +
+		"   --prefetch-templates           downloads all github gitignore and license\n" +
+		"                                  templates for later runs to use offline\n" +
+		"   --allow-squash-merge BOOL      allows squash merging pull requests\n" +
+		"   --allow-merge-commit BOOL      allows merge commits for pull requests\n" +
+		"   --allow-rebase-merge BOOL      allows rebase merging pull requests\n" +
+		"   --delete-branch-on-merge BOOL  deletes head branches after merge\n" +
+		"   --allow-auto-merge BOOL        allows auto-merging pull requests\n" +
+		"   --template NAMES               scaffolds project from comma separated templates\n" +
+		"                                  applied in order (go, docker), none disables\n" +
+		"                                  default_template from config\n" +
+		"   --dirs DIRS                    creates comma separated empty dirs with .gitkeep,\n" +
+		"                                  overrides default_dirs from config\n" +
+		"   --template-git URL             copies files of git repository into project,\n" +
+		"                                  replacing {{name}}, {{title}} and {{owner}}\n" +
+		"                                  and layering it over repositories named by\n" +
+		"                                  extends in its template.json, whose\n" +
+		"                                  description is used without --description,\n" +
+		"                                  files matching its .templateignore are skipped\n" +
+		"   --template-url URL             same as --template-git for tar.gz or zip archive\n" +
+		"                                  at URL, resuming the download on retry\n" +
+		"   --var NAME=VALUE               sets template repository variable instead of\n" +
+		"                                  prompting for it, can be repeated\n" +
+		"   --env-file PATH                sets template repository variables from .env\n" +
+		"                                  file, --var wins over it\n" +
+		"   --module-path PATH             overrides go module path for go template\n" +
+		"   --with-tests                   adds main_test.go to go template\n" +
+		"   --format                       runs language formatter on template files,\n" +
+		"                                  skipped with warning if not installed\n" +
+		"   --vscode                       creates .vscode settings for template language\n" +
+		"   --makefile                     creates Makefile with build, test, lint and\n" +
+		"                                  clean targets for template language\n" +
+		"   --mailmap                      creates .mailmap with git author identity\n" +
+		"   --authors                      creates AUTHORS with git author identity\n" +
+		"   --changelog                    creates CHANGELOG.md with Unreleased section\n" +
+		"   --version-file VERSION         creates VERSION file containing VERSION\n" +
+		"   --funding PLATFORM:ACCOUNT     adds account to .github/FUNDING.yml, e.g.\n" +
+		"                                  github:octocat, can be repeated\n" +
+		"   --no-default-files             skips README.md, .gitignore, default_template\n" +
+		"                                  and default_dirs, creating only files asked\n" +
+		"                                  for by other options\n" +
+		"   --git-hooks                    installs pre-commit hook into tracked .githooks\n" +
+		"   --api-field KEY=VALUE          adds field to repository create request,\n" +
+		"                                  VALUE is parsed as json, can be repeated\n" +
+		"   --dir-transform TRANSFORM      transforms local directory name, can be repeated\n" +
+		"                                  (lower, strip-prefix:PREFIX, strip-suffix:SUFFIX)\n" +
+		"   --notify-command CMD           runs shell command on success with result\n" +
+		"                                  json on stdin\n" +
+		"   --notify-webhook URL           posts result json to URL on success\n" +
+		"   --license KEY                  creates LICENSE from github license template\n" +
+		"   --readme-license-section       adds License section to README.md, requires\n" +
+		"                                  --license\n" +
+		"   --notice                       creates NOTICE file, requires --license Apache-2.0\n" +
+		"   --github-init                  lets github create initial commit with README.md\n" +
+		"                                  instead of creating README.md and .gitignore locally,\n" +
+		"                                  and .gitignore and LICENSE when their templates\n" +
+		"                                  are given\n" +
+		"   --gitignore-template NAME      uses github gitignore template for .gitignore\n" +
+		"   --no-gitignore-header          leaves out comment naming gitignore template\n" +
+		"   --reinit-existing              adds missing scaffolded files to an existing\n" +
+		"                                  project and pushes them\n" +
+		"   --clean                        deletes empty repository and local dir left\n" +
+		"                                  behind by a failed run, after confirmation\n" +
+		"   --check-name                   only checks whether repository name is free,\n" +
+		"                                  exits with 0 if available and 2 if taken\n" +
+		"   --auto-suffix                  uses NAME-2, NAME-3 and so on up to NAME-20\n" +
+		"                                  when repository name is taken\n" +
+		"   --print-clone-command          prints git clone command for repository and\n" +
+		"                                  exits without creating anything\n" +
+		"   --variable NAME=VALUE          sets github actions variable, can be repeated\n" +
+		"   --secret NAME=VALUE            sets github actions secret, can be repeated\n" +
+		"   --add GLOB                     stages only files matching GLOB for initial\n" +
+		"                                  commit instead of all, can be repeated\n" +
+		"   --signoff                      adds Signed-off-by line to initial commit\n" +
+		"   --fix-identity                 offers to amend initial commit when it was made\n" +
+		"                                  with a missing or wrong git identity\n" +
+		"   --grouped-commits              commits docs, license and ci files separately\n" +
+		"                                  with conventional commit messages\n" +
+		"   --delete-on-empty-push         deletes created repository if there is nothing\n" +
+		"                                  to commit instead of leaving it empty\n" +
+		"   --local-first                  scaffolds and commits in a new local repository\n" +
+		"                                  before creating github repository, so a failed\n" +
+		"                                  scaffold or nothing to commit leaves no empty\n" +
+		"                                  repository behind, rerunning it after creating\n" +
+		"                                  repository failed creates and pushes to it\n" +
+		"   --co-author \"NAME <EMAIL>\"     adds Co-authored-by trailer to initial commit,\n" +
+		"                                  can be repeated\n" +
+		"   --owner NAME                   creates repository under user or organization NAME\n" +
+		"   --owner-type TYPE              user or org, detected from --owner by default\n" +
+		"   --owner-fallback               creates repository under your user when the\n" +
+		"                                  organization does not exist or denies access\n" +
+		"   --host NAME                    github or gitea, selects token, api url and\n" +
+		"                                  clone host read from gh_apikey, api_url and\n" +
+		"                                  clone_host or gitea_apikey, gitea_api_url and\n" +
+		"                                  gitea_clone_host, overrides host from config\n" +
+		"   --retry-auth                   asks for a new token if github rejects the\n" +
+		"                                  configured one when creating repository\n" +
+		"   --print-plan FORMAT            prints resolved options and steps before running,\n" +
+		"                                  FORMAT is text or json\n" +
+		"   --dry-run                      prints plan and exits without changing anything\n" +
+		"   --validate-remote              with --dry-run, checks token, name availability\n" +
+		"                                  and organization membership with read-only\n" +
+		"                                  api requests\n" +
+		"   --emit-script PATH             writes shell script creating, cloning,\n" +
+		"                                  scaffolding, committing and pushing project to\n" +
+		"                                  PATH (- for stdout) instead of running, token\n" +
+		"                                  is read from GITHUB_TOKEN (GITEA_TOKEN)\n" +
+		"   --fork OWNER/NAME              forks repository instead of creating one and\n" +
+		"                                  adds upstream remote, NAME defaults to fork's\n" +
+		"   --contribute OWNER/NAME        clones existing repository instead of creating\n" +
+		"                                  one, commits missing files to a new branch\n" +
+		"                                  (--branch, default create-project) and opens\n" +
+		"                                  a pull request\n" +
+		"   --base BRANCH                  with --contribute, starts new branch from BRANCH\n" +
+		"                                  and opens pull request against it, defaults\n" +
+		"                                  to default branch\n" +
+		"   --title TEXT                   uses TEXT as README.md title, NAME defaults to\n" +
+		"                                  TEXT in kebab-case\n" +
+		"   --description TEXT             sets repository description, added to README.md\n" +
+		"   --description-max-len N        truncates longer descriptions (default 350)\n" +
+		"   --description-from-git         reads description from .project metadata file\n" +
+		"                                  or else first README.md paragraph of existing\n" +
+		"                                  project when --description is unset\n" +
+		"   --deploy-key PATH              generates ed25519 key at PATH and adds its\n" +
+		"                                  public key as read-only deploy key\n" +
+		"   --deploy-key-write             gives deploy key write access\n" +
+		"   --no-network                   fails before doing anything if the run would\n" +
+		"                                  need network access\n" +
+		"   --prune-default-labels         deletes labels github creates by default\n" +
+		"   --as-template                  marks created repository as template repository\n" +
+		"   --ruleset FILE                 creates repository ruleset from json FILE\n" +
+		"   --verify-ssh                   checks ssh access to github before creating\n" +
+		"                                  anything\n" +
+		"   --metrics                      prints how long each phase of the run took\n" +
+		"   --summary-only                 prints one summary line instead of each step,\n" +
+		"                                  errors are still printed\n" +
+		"   --json                         prints each step, warning and error as json\n" +
+		"                                  object with level and message, one per line\n" +
+		"   --color MODE                   highlights warnings and errors, MODE is auto\n" +
+		"                                  (default, only on a terminal and without\n" +
+		"                                  NO_COLOR), always or never\n" +
+		"   --log-file PATH                appends all output, including steps hidden by\n" +
+		"                                  --summary-only, to PATH\n" +
+		"   --edit                         opens project in $VISUAL, $EDITOR or editor\n" +
+		"                                  from config after creating it\n" +
+		"   --trace                        prints every api request and response to\n" +
+		"                                  stderr, token redacted\n" +
+		"   --strict-tls-min-version V     refuses api connections older than TLS V, 1.2\n" +
+		"                                  (default) or 1.3, overrides tls_min_version\n" +
+		"   --since DURATION               reuses repository with same name created within\n" +
+		"                                  DURATION (e.g. 10m) instead of creating it\n" +
+		"   --timeout DURATION             aborts run, including clone and push, once it\n" +
+		"                                  took longer than DURATION (e.g. 2m)\n" +
+		"   --here                         creates project in current directory instead of\n" +
+		"                                  projects_dir\n" +
+		"   --dir-permissions-check        warns if projects_dir or a parent is\n" +
+		"                                  world-writable\n" +
+		"   --branch NAME                  pushes initial commit to branch NAME (default main)\n" +
+		"   --max-retries N                retries for a single failed operation\n" +
+		"   --max-retries-total N          retries shared by all operations of the run\n" +
+		"   --retry-on CONDITIONS          comma separated failures to retry\n" +
+		"                                  (default 5xx,429,timeout,connreset)\n" +
+		"   --retry-jitter                 waits random time up to backoff between retries\n",
 		os.Args[0],
 	)
 }
 
 func iferr(msg string, err error) {
 	if err != nil {
-		fmt.Fprintf(os.Stderr, msg, err)
+		if runCtx.Err() != nil {
+			err = context.Cause(runCtx)
+		}
+		output.error(msg, err)
 		os.Exit(1)
 	}
 }
 
-func getConfigPath() string {
-	cdir, err := os.UserConfigDir()
-	iferr("Failed to get user config dir: %v\n", err)
-	return path.Join(cdir, "create-project", "config")
+func isMergeSetting(field string) bool {
+	for _, f := range mergeSettingFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
 }
 
-func (c *appConfig) isValid() bool {
-	return c.ghUsername != "" && c.ghApiKey != "" && c.projDir != ""
+func parseBool(name string, v string) bool {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		output.error("Invalid boolean for %s: %s\n", name, v)
+		os.Exit(1)
+	}
+	return b
 }
 
-func (c *appConfig) load() {
-	configPath := getConfigPath()
-	f, err := os.Open(configPath)
-	iferr("Failed to open config file: %v\n", err)
-	defer f.Close()
+func parseCount(name string, v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		output.error("Invalid count for %s: %s\n", name, v)
+		os.Exit(1)
+	}
+	return n
+}
 
-	s := bufio.NewScanner(f)
-	for s.Scan() {
-		kv := strings.Split(s.Text(), "=")
-		k := strings.Trim(kv[0], " ")
-		v := strings.Trim(kv[1], " ")
+func nextArg(args []string, i *int) string {
+	if *i+1 >= len(args) {
+		output.error("Missing value for %s\n", args[*i])
+		printUsage(output.raw())
+		os.Exit(1)
+	}
+	*i++
+	return args[*i]
+}
 
-		switch k {
-		case "gh_username":
-			c.ghUsername = v
-		case "gh_apikey":
-			c.ghApiKey = v
-		case "projects_dir":
-			c.projDir = v
-		default:
-			fmt.Fprintf(os.Stderr, "Unknown config field: %s\n", k)
-		}
+// parseApiField splits KEY=VALUE and decodes VALUE as json so booleans and
+// numbers keep their type. Anything that is not valid json is sent as string.
+func parseApiField(s string) (string, any) {
+	k, raw, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		output.error("Invalid api field, expected KEY=VALUE: %s\n", s)
+		os.Exit(1)
 	}
 
-	if !c.isValid() {
-		fmt.Fprintf(os.Stderr, "Config is missing required fields\n")
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		v = raw
+	}
+
+	return k, v
+}
+
+func parseCoAuthor(s string) string {
+	name, email, ok := strings.Cut(strings.TrimSpace(s), "<")
+	if !ok || strings.TrimSpace(name) == "" || !strings.HasSuffix(email, ">") || !strings.Contains(email, "@") {
+		output.error("Invalid co-author, expected \"NAME <EMAIL>\": %s\n", s)
 		os.Exit(1)
 	}
+	return strings.TrimSpace(name) + " <" + email
 }
 
-func createRepo(name string, config *appConfig) {
-	client := http.Client{}
+// projectTitle is the title README.md starts with, --title as given or
+// else built from the project name.
+func (opts *appOptions) projectTitle() string {
+	if opts.title != "" {
+		return opts.title
+	}
+	return buildTitle(opts.projName)
+}
 
-	req, err := http.NewRequest(
-		http.MethodPost,
-		"https://api.github.com/user/repos",
-		strings.NewReader(`{"name":"` + name + `"}`),
-	)
-	iferr("Failed to create request: %v\n", err)
+// latinLetters transliterates the common accented and other Latin letters
+// that have a plain ASCII spelling.
+var latinLetters = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'œ': "oe",
+	'ř': "r",
+	'ß': "ss",
+	'ś': "s", 'š': "s", 'ş': "s",
+	'ť': "t", 'ţ': "t",
+	'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+}
 
-	req.Header.Add("User-Agent", "Go")
-	req.Header.Add("Authorization", "token " + config.ghApiKey)
+// slugify turns a free text title into a project name: lowercase letters
+// and digits, with every run of anything else becoming one hyphen. Latin
+// letters like é become their ASCII spelling, a title with other letters
+// is rejected rather than losing part of it.
+func slugify(title string) string {
+	slug := strings.Builder{}
+	pendingHyphen := false
 
-	res, err := client.Do(req)
-	iferr("Failed to execute request: %v\n", err)
-	defer res.Body.Close()
+	for _, r := range strings.ToLower(title) {
+		ascii, ok := latinLetters[r]
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			ascii, ok = string(r), true
+		}
 
-	if res.StatusCode != http.StatusCreated {
-		fmt.Fprintln(os.Stderr, "Failed to create repository")
+		switch {
+		case ok:
+			if pendingHyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			pendingHyphen = false
+			slug.WriteString(ascii)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			output.error("Title has letters that cannot be spelled in ASCII, pass NAME too: %q\n", title)
+			os.Exit(1)
+		case r != '\'':
+			pendingHyphen = true
+		}
+	}
 
-		data, err := io.ReadAll(res.Body)
-		iferr("Failed to read response body: %v\n", err)
+	if slug.Len() == 0 {
+		output.error("Title has no letters or digits to derive a name from: %q\n", title)
+		os.Exit(1)
+	}
+	return slug.String()
+}
 
-		pretty := bytes.Buffer{}
-		err = json.Indent(&pretty, data, "", "  ")
-		iferr("Failed to indent json: %v\n", err)
+// parseDirs parses a comma separated list of dirs relative to the project.
+// An empty list is allowed so --dirs "" can drop default_dirs.
+func parseDirs(s string) []string {
+	dirs := []string{}
 
-		fmt.Fprintln(os.Stderr, pretty.String())
-		os.Exit(1)
+	for _, d := range strings.Split(s, ",") {
+		d = strings.TrimSuffix(strings.TrimSpace(d), "/")
+		if d == "" {
+			continue
+		}
+		if !filepath.IsLocal(d) {
+			output.error("Invalid dir, expected path inside project: %s\n", d)
+			os.Exit(1)
+		}
+		dirs = append(dirs, d)
 	}
+
+	return dirs
 }
 
-func cloneRepo(name string, config *appConfig) {
-	cmd := exec.Command(
-		"/bin/git",
-		"clone",
-		fmt.Sprintf("git@github.com:%s/%s.git", config.ghUsername, name),
-	)
-	cmd.Dir = config.projDir
-	err := cmd.Run()
-	iferr("Failed to clone repository: %v\n", err)
+func isValidDirTransform(t string) bool {
+	return t == "lower" ||
+		strings.HasPrefix(t, "strip-prefix:") ||
+		strings.HasPrefix(t, "strip-suffix:")
 }
 
-func commitChanges(projPath string) {
-	cmd := exec.Command("/bin/git", "add", ".")
-	cmd.Dir = projPath
-	err := cmd.Run()
-	iferr("Failed to add changes: %v\n", err)
+func transformDirName(name string, transforms []string) string {
+	for _, t := range transforms {
+		switch {
+		case t == "lower":
+			name = strings.ToLower(name)
+		case strings.HasPrefix(t, "strip-prefix:"):
+			name = strings.TrimPrefix(name, strings.TrimPrefix(t, "strip-prefix:"))
+		case strings.HasPrefix(t, "strip-suffix:"):
+			name = strings.TrimSuffix(name, strings.TrimPrefix(t, "strip-suffix:"))
+		}
+	}
 
-	cmd = exec.Command("/bin/git", "commit", "-m", "initial commit")
-	cmd.Dir = projPath
-	err = cmd.Run()
-	iferr("Failed to commit changes: %v\n", err)
+	if name == "" {
+		output.error("Dir transforms produced empty directory name\n")
+		os.Exit(1)
+	}
 
-	cmd = exec.Command("/bin/git", "push", "origin", "master")
-	cmd.Dir = projPath
-	err = cmd.Run()
-	iferr("Failed to push changes: %v\n", err)
+	return name
 }
 
-func createFile(name string) *os.File {
-	f, err := os.Create(name)
-	iferr("Failed to create file: %v\n", err)
+const defaultDescriptionMaxLen = 350
 
-	err = f.Chmod(0644)
-	iferr("Failed to change file mode: %v\n", err)
+// truncateDescription cuts description to max runes, ellipsis included, and
+// warns about it.
+func truncateDescription(description string, max int) string {
+	if strings.ContainsAny(description, "\r\n") {
+		output.error("Description must be a single line\n")
+		os.Exit(1)
+	}
 
-	return f
-}
+	runes := []rune(description)
+	if len(runes) <= max {
+		return description
+	}
 
-func buildMdTitle(s string) string {
-	title := strings.Builder{}
+	output.warn("description is longer than %d characters, truncating\n", max)
+	return string(runes[:max - 1]) + "…"
+}
 
-	title.WriteString("# ")
+// readProjectDescription reads the description field of the .project
+// metadata file some migrated repositories carry. It uses the config file's
+// key = value format and returns "" when there is no such file or field.
+func readProjectDescription(projPath string) string {
+	f, err := os.Open(filepath.Join(projPath, ".project"))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	iferr("Failed to open .project: %v\n", err)
+	defer f.Close()
 
-	for i, word := range strings.Split(s, "-") {
-		if i > 0 {
-			title.WriteString(" ")
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		k, v, ok := strings.Cut(stripBom(s.Text()), "=")
+		if ok && strings.TrimSpace(k) == "description" {
+			return strings.TrimSpace(v)
 		}
-		capitalized := strings.ToUpper(string(word[0])) + word[1:]
-		title.WriteString(capitalized)
 	}
+	iferr("Failed to read .project: %v\n", s.Err())
 
-	return title.String()
+	return ""
 }
 
-func createReadmeGitignore(projName string, projPath string) {
-	gitignore := createFile(projPath + "/.gitignore")
-	gitignore.Close()
+// readReadmeDescription returns the first paragraph of README.md that is not
+// a heading, badge line or html, joined into one line.
+func readReadmeDescription(projPath string) string {
+	data, err := os.ReadFile(filepath.Join(projPath, "README.md"))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	iferr("Failed to read README.md: %v\n", err)
 
-	readme := createFile(projPath + "/README.md")
-	title := buildMdTitle(projName)
-	readme.WriteString(title)
-	readme.Close()
-}
+	paragraph := []string{}
+	inFence := false
+	for _, line := range strings.Split(stripBom(string(data)), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+			continue
+		}
 
-func generateConfig() {
-	configPath := getConfigPath()
+		// An underline makes the lines above it a heading, not a paragraph.
+		if line != "" && strings.Trim(line, "=-") == "" && !inFence {
+			paragraph = paragraph[:0]
+			continue
+		}
 
-	err := os.MkdirAll(path.Dir(configPath), 0700)
-	iferr("failed to create config folder: %v\n", err)
+		skip := inFence || line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<") ||
+			strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "![")
+		if skip {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
 
-	f := createFile(configPath)
-	defer f.Close()
+	return strings.Join(paragraph, " ")
+}
 
-	f.WriteString(
-		"gh_apikey    = github api key\n" +
-		"gh_username  = github username\n" +
-		"projects_dir = /absolute/path/to/dir\n",
-	)
-	fmt.Printf("Config created %v\n", configPath)
+// readExistingDescription prefers .project over README.md.
+func readExistingDescription(projPath string) string {
+	if description := readProjectDescription(projPath); description != "" {
+		return description
+	}
+	return readReadmeDescription(projPath)
 }
 
-func confirm(projPath string) {
-	fmt.Printf("Create project %v (y/n)\n", projPath)
+// validateOwner checks owner against GitHub's rules for user and
+// organization names.
+func validateOwner(owner string) string {
+	valid := owner != "" && len(owner) <= 39 && !strings.HasPrefix(owner, "-") &&
+		!strings.HasSuffix(owner, "-") && !strings.Contains(owner, "--")
+	for _, r := range owner {
+		isAlnum := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if !isAlnum && r != '-' {
+			valid = false
+		}
+	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
+	if !valid {
+		output.error("Invalid owner: %q\n", owner)
+		os.Exit(1)
+	}
+	return owner
+}
 
-	err := scanner.Err()
-	iferr("Failed to scan user input: %v\n", err)
+// validateProjName exits unless name is made of words of letters, digits,
+// dots and underscores joined by single hyphens. Dots alone would name the
+// projects dir or its parent.
+func validateProjName(name string) string {
+	valid := strings.Trim(name, ".") != ""
+	for _, word := range strings.Split(name, "-") {
+		if word == "" {
+			valid = false
+		}
+		for _, r := range word {
+			isAlnum := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+			if !isAlnum && r != '.' && r != '_' {
+				valid = false
+			}
+		}
+	}
 
-	input := scanner.Text()
-	if input != "y" && input != "" {
-		os.Exit(0)
+	if !valid {
+		output.error("Invalid project name: %q\n", name)
+		os.Exit(1)
 	}
+	return name
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Not enough arguments\n")
-		printUsage(os.Stderr)
-		os.Exit(1)
+func readProjName(file string) string {
+	data, err := os.ReadFile(file)
+	iferr("Failed to read project name: %v\n", err)
+	return validateProjName(strings.TrimSpace(stripBom(string(data))))
+}
+
+func parseArgs(args []string) appOptions {
+	opts := appOptions{
+		mergeSettings: map[string]bool{},
+		apiFields: map[string]any{},
+		vars: map[string]string{},
+		funding: map[string][]string{},
+		maxRetries: -1,
+		maxRetriesTotal: -1,
+		descriptionMaxLen: defaultDescriptionMaxLen,
+		color: "auto",
 	}
 
-	if strings.HasPrefix(os.Args[1], "--") {
-		if os.Args[1] == "--help" {
-			printUsage(os.Stdout)
-			os.Exit(0)
+	// owner of OWNER/NAME positionals, which all have to agree.
+	positionalOwner := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "--") {
+			owner, name, ok := strings.Cut(arg, "/")
+			if !ok {
+				opts.projNames = append(opts.projNames, validateProjName(arg))
+				continue
+			}
+
+			if positionalOwner != "" && positionalOwner != owner {
+				output.error("Conflicting owners: %s and %s\n", positionalOwner, owner)
+				os.Exit(1)
+			}
+			positionalOwner = validateOwner(owner)
+			opts.projNames = append(opts.projNames, validateProjName(name))
+			continue
 		}
 
-		if os.Args[1] == "--gen-config" {
+		flagStart := i
+
+		switch arg {
+		case "--name-from":
+			opts.projNames = append(opts.projNames, readProjName(nextArg(args, &i)))
+			// The name joins the positional ones, so it must not be passed on
+			// to the runs of a multi-project invocation.
+			continue
+		case "--help":
+			printUsage(output.stdout)
+			os.Exit(0)
+		case "--gen-config":
 			generateConfig()
 			os.Exit(0)
+		case "--list":
+			listRegistry()
+			os.Exit(0)
+		case "--validate-template":
+			validateTemplate(nextArg(args, &i))
+			os.Exit(0)
+		case "--self-update":
+			selfUpdate()
+			os.Exit(0)
+		case "--prefetch-templates":
+			opts.prefetchTemplates = true
+		case "--allow-squash-merge", "--allow-merge-commit",
+			"--allow-rebase-merge", "--delete-branch-on-merge", "--allow-auto-merge":
+			field := strings.ReplaceAll(arg[2:], "-", "_")
+			opts.mergeSettings[field] = parseBool(arg, nextArg(args, &i))
+		case "--template":
+			opts.templates = parseTemplates(nextArg(args, &i))
+		case "--with-tests":
+			opts.withTests = true
+		case "--format":
+			opts.format = true
+		case "--vscode":
+			opts.vscode = true
+		case "--makefile":
+			opts.makefile = true
+		case "--dirs":
+			opts.dirs = parseDirs(nextArg(args, &i))
+		case "--template-git":
+			opts.templateGit = nextArg(args, &i)
+		case "--template-url":
+			opts.templateUrl = nextArg(args, &i)
+			if !isArchiveUrl(opts.templateUrl) {
+				output.error("Invalid template url, expected http(s) url of .tar.gz, .tgz or .zip: %s\n", opts.templateUrl)
+				os.Exit(1)
+			}
+		case "--var":
+			k, v, ok := strings.Cut(nextArg(args, &i), "=")
+			if !ok || k == "" {
+				output.error("Invalid template variable, expected NAME=VALUE: %s\n", args[i])
+				os.Exit(1)
+			}
+			opts.vars[k] = v
+		case "--env-file":
+			opts.envFile = nextArg(args, &i)
+		case "--module-path":
+			opts.modulePath = nextArg(args, &i)
+			if !isValidModulePath(opts.modulePath) {
+				output.error("Invalid module path: %s\n", opts.modulePath)
+				os.Exit(1)
+			}
+		case "--mailmap":
+			opts.mailmap = true
+		case "--authors":
+			opts.authors = true
+		case "--changelog":
+			opts.changelog = true
+		case "--version-file":
+			opts.versionFile = nextArg(args, &i)
+			if !versionPattern.MatchString(opts.versionFile) {
+				output.error("Invalid version, expected e.g. 0.1.0: %s\n", opts.versionFile)
+				os.Exit(1)
+			}
+		case "--funding":
+			parseFunding(nextArg(args, &i), opts.funding)
+		case "--no-default-files":
+			opts.noDefaultFiles = true
+		case "--git-hooks":
+			opts.gitHooks = true
+		case "--dir-transform":
+			t := nextArg(args, &i)
+			if !isValidDirTransform(t) {
+				output.error("Unknown dir transform: %s\n", t)
+				os.Exit(1)
+			}
+			opts.dirTransforms = append(opts.dirTransforms, t)
+		case "--notify-command":
+			opts.notifyCommand = nextArg(args, &i)
+		case "--notify-webhook":
+			opts.notifyWebhook = nextArg(args, &i)
+		case "--license":
+			opts.license = nextArg(args, &i)
+		case "--readme-license-section":
+			opts.readmeLicenseSection = true
+		case "--notice":
+			opts.notice = true
+		case "--github-init":
+			opts.githubInit = true
+		case "--gitignore-template":
+			opts.gitignoreTemplate = nextArg(args, &i)
+		case "--no-gitignore-header":
+			opts.noGitignoreHeader = true
+		case "--reinit-existing":
+			opts.reinitExisting = true
+		case "--clean":
+			opts.clean = true
+		case "--check-name":
+			opts.checkName = true
+		case "--auto-suffix":
+			opts.autoSuffix = true
+		case "--print-clone-command":
+			opts.printCloneCommand = true
+		case "--variable":
+			v := nextArg(args, &i)
+			name, value, ok := strings.Cut(v, "=")
+			if !ok || name == "" {
+				output.error("Invalid variable, expected NAME=VALUE: %s\n", v)
+				os.Exit(1)
+			}
+			opts.variables = append(opts.variables, [2]string{name, value})
+		case "--secret":
+			v := nextArg(args, &i)
+			name, value, ok := strings.Cut(v, "=")
+			if !ok || name == "" {
+				output.error("Invalid secret, expected NAME=VALUE: %s\n", name)
+				os.Exit(1)
+			}
+			opts.secrets = append(opts.secrets, [2]string{name, value})
+		case "--add":
+			opts.addPatterns = append(opts.addPatterns, nextArg(args, &i))
+		case "--co-author":
+			opts.coAuthors = append(opts.coAuthors, parseCoAuthor(nextArg(args, &i)))
+		case "--signoff":
+			opts.signoff = true
+		case "--fix-identity":
+			opts.fixIdentity = true
+		case "--grouped-commits":
+			opts.groupedCommits = true
+		case "--delete-on-empty-push":
+			opts.deleteOnEmptyPush = true
+		case "--local-first":
+			opts.localFirst = true
+		case "--owner":
+			opts.owner = nextArg(args, &i)
+		case "--owner-fallback":
+			opts.ownerFallback = true
+		case "--retry-auth":
+			opts.retryAuth = true
+		case "--owner-type":
+			opts.ownerType = nextArg(args, &i)
+			if opts.ownerType != "user" && opts.ownerType != "org" {
+				output.error("Invalid owner type: %s\n", opts.ownerType)
+				os.Exit(1)
+			}
+		case "--print-plan":
+			opts.planFormat = nextArg(args, &i)
+			if opts.planFormat != "text" && opts.planFormat != "json" {
+				output.error("Invalid plan format: %s\n", opts.planFormat)
+				os.Exit(1)
+			}
+		case "--dry-run":
+			opts.dryRun = true
+		case "--emit-script":
+			opts.emitScript = nextArg(args, &i)
+		case "--validate-remote":
+			opts.validateRemote = true
+		case "--fork":
+			opts.fork = nextArg(args, &i)
+			splitFullName(opts.fork)
+		case "--contribute":
+			opts.contribute = nextArg(args, &i)
+			splitFullName(opts.contribute)
+		case "--base":
+			opts.base = nextArg(args, &i)
+		case "--description":
+			opts.description = nextArg(args, &i)
+		case "--title":
+			opts.title = strings.TrimSpace(nextArg(args, &i))
+		case "--description-from-git":
+			opts.descriptionFromGit = true
+		case "--description-max-len":
+			opts.descriptionMaxLen = parseCount(arg, nextArg(args, &i))
+			if opts.descriptionMaxLen == 0 {
+				output.error("--description-max-len must be positive\n")
+				os.Exit(1)
+			}
+		case "--deploy-key":
+			opts.deployKey = nextArg(args, &i)
+			if _, err := os.Stat(opts.deployKey); err == nil {
+				output.error("Deploy key already exists: %s\n", opts.deployKey)
+				os.Exit(1)
+			}
+		case "--deploy-key-write":
+			opts.deployKeyWrite = true
+		case "--no-network":
+			opts.noNetwork = true
+		case "--prune-default-labels":
+			opts.pruneLabels = true
+		case "--as-template":
+			opts.asTemplate = true
+		case "--ruleset":
+			opts.ruleset = nextArg(args, &i)
+			opts.rulesetBody = readRuleset(opts.ruleset)
+		case "--verify-ssh":
+			opts.verifySsh = true
+		case "--metrics":
+			opts.metrics = true
+		case "--summary-only":
+			opts.summaryOnly = true
+		case "--json":
+			opts.json = true
+		case "--color":
+			opts.color = nextArg(args, &i)
+			if !slices.Contains([]string{"auto", "always", "never"}, opts.color) {
+				output.error("Invalid color, expected auto, always or never: %s\n", opts.color)
+				os.Exit(1)
+			}
+		case "--log-file":
+			opts.logFile = nextArg(args, &i)
+		case "--edit":
+			opts.edit = true
+		case "--trace":
+			opts.trace = true
+		case "--strict-tls-min-version":
+			opts.tlsMinVersion = parseTlsVersion(arg, nextArg(args, &i))
+		case "--host":
+			opts.host = parseHost(nextArg(args, &i))
+		case "--since":
+			v := nextArg(args, &i)
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				output.error("Invalid duration for --since: %s\n", v)
+				os.Exit(1)
+			}
+			opts.since = d
+		case "--timeout":
+			v := nextArg(args, &i)
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				output.error("Invalid duration for --timeout: %s\n", v)
+				os.Exit(1)
+			}
+			opts.timeout = d
+		case "--dir-permissions-check":
+			opts.dirPermissionsCheck = true
+		case "--here":
+			opts.here = true
+		case "--branch":
+			opts.branch = nextArg(args, &i)
+		case "--max-retries":
+			opts.maxRetries = parseCount(arg, nextArg(args, &i))
+		case "--max-retries-total":
+			opts.maxRetriesTotal = parseCount(arg, nextArg(args, &i))
+		case "--retry-on":
+			opts.retryOn = parseRetryConditions(nextArg(args, &i))
+		case "--retry-jitter":
+			opts.retryJitter = true
+		case "--api-field":
+			k, v := parseApiField(nextArg(args, &i))
+			opts.apiFields[k] = v
+		default:
+			output.error("Unknown option: %s\n", arg)
+			printUsage(output.raw())
+			os.Exit(1)
 		}
 
-		fmt.Fprintf(os.Stderr, "Unknown option: %s\n", os.Args[1])
-		printUsage(os.Stderr)
+		opts.flagArgs = append(opts.flagArgs, args[flagStart:i + 1]...)
+	}
+
+	if opts.title != "" && len(opts.projNames) > 1 {
+		output.error("--title takes a single project name\n")
+		os.Exit(1)
+	}
+	if opts.title != "" && len(opts.projNames) == 0 {
+		opts.projNames = []string{slugify(opts.title)}
+	}
+
+	if len(opts.projNames) > 0 {
+		opts.projName = opts.projNames[0]
+	}
+	if positionalOwner != "" {
+		if opts.owner != "" && opts.owner != positionalOwner {
+			output.error("Conflicting owners: --owner %s and %s\n", opts.owner, positionalOwner)
+			os.Exit(1)
+		}
+		opts.owner = positionalOwner
+		opts.flagArgs = append(opts.flagArgs, "--owner", positionalOwner)
+	}
+	if len(opts.projNames) > 1 && (opts.fork != "" || opts.contribute != "" || opts.deployKey != "") {
+		output.error("--fork, --contribute and --deploy-key take a single project name\n")
 		os.Exit(1)
 	}
 
-	fmt.Println("Loading config file...")
-	config := appConfig{}
-	config.load()
+	opts.description = truncateDescription(expandShortcodes(opts.description), opts.descriptionMaxLen)
+
+	if opts.groupedCommits && len(opts.addPatterns) > 0 {
+		output.error("--grouped-commits cannot be combined with --add\n")
+		os.Exit(1)
+	}
+
+	if opts.validateRemote && !opts.dryRun {
+		output.error("--validate-remote requires --dry-run\n")
+		os.Exit(1)
+	}
+
+	if opts.deployKeyWrite && opts.deployKey == "" {
+		output.error("--deploy-key-write requires --deploy-key\n")
+		os.Exit(1)
+	}
+
+	if opts.projName == "" && opts.fork != "" {
+		_, opts.projName = splitFullName(opts.fork)
+	}
+
+	if opts.contribute != "" {
+		owner, name := splitFullName(opts.contribute)
+		if opts.projName == "" {
+			opts.projName = name
+		}
+		if opts.owner == "" {
+			opts.owner = owner
+		}
+	}
+
+	// Prefetching goes through the config like a run but creates nothing.
+	if opts.prefetchTemplates {
+		return opts
+	}
+
+	if opts.projName == "" {
+		output.error("Not enough arguments\n")
+		printUsage(output.raw())
+		os.Exit(1)
+	}
+
+	if opts.templateGit != "" && opts.templateUrl != "" {
+		output.error("--template-git cannot be combined with --template-url\n")
+		os.Exit(1)
+	}
+
+	if (len(opts.vars) > 0 || opts.envFile != "") && opts.templateSource() == "" {
+		output.error("--var and --env-file require --template-git or --template-url\n")
+		os.Exit(1)
+	}
+
+	if opts.readmeLicenseSection && opts.noDefaultFiles {
+		output.error("--readme-license-section cannot be combined with --no-default-files\n")
+		os.Exit(1)
+	}
+
+	if opts.readmeLicenseSection && opts.license == "" {
+		output.error("--readme-license-section requires --license\n")
+		os.Exit(1)
+	}
+
+	if opts.notice && !usesNotice(opts.license) {
+		output.error("--notice requires --license Apache-2.0\n")
+		os.Exit(1)
+	}
+
+	if opts.localFirst && (opts.githubInit || opts.fork != "") {
+		output.error("--local-first cannot be combined with --github-init or --fork\n")
+		os.Exit(1)
+	}
+
+	if opts.emitScript != "" {
+		unsupported := map[string]bool{
+			"--fork": opts.fork != "",
+			"--contribute": opts.contribute != "",
+			"--reinit-existing": opts.reinitExisting,
+			"--clean": opts.clean,
+			"--check-name": opts.checkName,
+			"--dry-run": opts.dryRun,
+			"--local-first": opts.localFirst,
+			"--grouped-commits": opts.groupedCommits,
+			"--prune-default-labels": opts.pruneLabels,
+			"--as-template": opts.asTemplate,
+			"--ruleset": opts.ruleset != "",
+			"--variable": len(opts.variables) > 0,
+			"--secret": len(opts.secrets) > 0,
+			"--deploy-key": opts.deployKey != "",
+			"--since": opts.since > 0,
+			"--fix-identity": opts.fixIdentity,
+			"--delete-on-empty-push": opts.deleteOnEmptyPush,
+			"--notify-command": opts.notifyCommand != "",
+			"--notify-webhook": opts.notifyWebhook != "",
+			"--edit": opts.edit,
+			"--verify-ssh": opts.verifySsh,
+			"--owner-fallback": opts.ownerFallback,
+			"--retry-auth": opts.retryAuth,
+		}
+		for _, flag := range slices.Sorted(maps.Keys(unsupported)) {
+			if unsupported[flag] {
+				output.error("--emit-script cannot be combined with %s\n", flag)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if opts.autoSuffix && (opts.fork != "" || opts.contribute != "" || opts.reinitExisting || opts.clean || opts.checkName || opts.since > 0) {
+		output.error("--auto-suffix only applies when creating a new repository\n")
+		os.Exit(1)
+	}
+
+	if opts.base != "" && opts.contribute == "" {
+		output.error("--base requires --contribute\n")
+		os.Exit(1)
+	}
+
+	if opts.contribute != "" && (opts.fork != "" || opts.localFirst || opts.githubInit || opts.reinitExisting) {
+		output.error("--contribute cannot be combined with --fork, --local-first, --github-init or --reinit-existing\n")
+		os.Exit(1)
+	}
+
+	return opts
+}
+
+const configEnv = "CREATE_PROJECT_CONFIG"
+
+// stripBom drops the UTF-8 byte order mark some editors put at the start of
+// a file, which would otherwise end up in the first key read from it.
+func stripBom(s string) string {
+	return strings.TrimPrefix(s, "\uFEFF")
+}
+
+func getConfigPath() string {
+	if p := os.Getenv(configEnv); p != "" {
+		return p
+	}
+
+	cdir, err := os.UserConfigDir()
+	if err != nil {
+		output.error("Failed to get user config dir: %v\n", err)
+		output.error("Set %s or use --config to point to the config file\n", configEnv)
+		os.Exit(1)
+	}
+	return filepath.Join(cdir, "create-project", "config")
+}
 
-	projName := os.Args[1]
-	projPath := config.projDir + "/" + projName
+// applyConfigFlag removes --config PATH from args and exports it as
+// CREATE_PROJECT_CONFIG, so it is honored by options handled while parsing
+// and by child runs alike.
+func applyConfigFlag(args []string) []string {
+	rest := []string{}
 
-	confirm(projPath)
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--config" {
+			rest = append(rest, args[i])
+			continue
+		}
+
+		p, err := filepath.Abs(nextArg(args, &i))
+		iferr("Failed to resolve config path: %v\n", err)
+		os.Setenv(configEnv, p)
+	}
+
+	return rest
+}
+
+func (c *appConfig) isValid() bool {
+	return c.ghUsername != "" && c.ghApiKey != ""
+}
+
+// load reads the config file. The token, api url and clone host used are
+// the ones of c.host, set from --host beforehand or else from the host
+// field, github by default.
+func (c *appConfig) load() {
+	configPath := getConfigPath()
+	c.mergeSettings = map[string]bool{}
+	c.apiKeys = map[string]string{}
+	c.apiUrls = map[string]string{}
+	c.cloneHosts = map[string]string{}
+	host := ""
+	c.retry = newRetryBudget()
+	c.confirmDefault = true
+	c.gitignoreHeader = true
+
+	f, err := os.Open(configPath)
+	iferr("Failed to open config file: %v\n", err)
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := stripBom(s.Text())
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
 
-	fmt.Println("Creating GitHub repository...")
-	createRepo(projName, &config)
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		k = strings.Trim(k, " ")
+		v = strings.Trim(v, " ")
 
-	fmt.Printf("Cloning repository into %s...\n", projPath)
-	cloneRepo(projName, &config)
+		if _, ok := presetName(k); ok {
+			continue
+		}
 
-	fmt.Println("Creating README.md and .gitignore...")
-	createReadmeGitignore(projName, projPath)
+		switch k {
+		case "gh_username":
+			c.ghUsername = v
+		case "gh_apikey":
+			c.apiKeys["github"] = v
+		case "gitea_apikey":
+			c.apiKeys["gitea"] = v
+		case "host":
+			host = parseHost(v)
+		case "projects_dir":
+			c.projDir = v
+		case "default_template":
+			c.defaultTemplates = parseTemplates(v)
+		case "api_url":
+			c.apiUrls["github"] = parseApiUrl(k, v)
+		case "gitea_api_url":
+			c.apiUrls["gitea"] = parseApiUrl(k, v)
+		case "clone_host":
+			c.cloneHosts["github"] = parseCloneHost(k, v)
+		case "gitea_clone_host":
+			c.cloneHosts["gitea"] = parseCloneHost(k, v)
+		case "editor":
+			c.editor = v
+		case "create_endpoint":
+			c.createEndpoint = parseEndpointPath(v)
+		case "default_dirs":
+			c.defaultDirs = parseDirs(v)
+		case "confirm_default":
+			if v != "yes" && v != "no" {
+				output.error("Invalid confirm_default, expected yes or no: %s\n", v)
+				os.Exit(1)
+			}
+			c.confirmDefault = v == "yes"
+		case "max_retries":
+			c.retry.perOperation = parseCount(k, v)
+		case "max_retries_total":
+			c.retry.remaining = parseCount(k, v)
+		case "retry_on":
+			c.retry.retryOn = parseRetryConditions(v)
+		case "newline":
+			c.textStyle.crlf = parseNewline(v)
+		case "charset":
+			c.textStyle.bom = parseCharset(v)
+		case "tls_min_version":
+			c.tlsMinVersion = parseTlsVersion(k, v)
+		case "gitignore_header":
+			c.gitignoreHeader = parseBool(k, v)
+		case "retry_jitter":
+			c.retry.jitter = parseBool(k, v)
+		default:
+			if isMergeSetting(k) {
+				c.mergeSettings[k] = parseBool(k, v)
+				continue
+			}
+			output.error("Unknown config field: %s\n", k)
+		}
+	}
 
-	fmt.Println("Committing changes to the repository...")
-	commitChanges(projPath)
+	if c.host == "" {
+		c.host = host
+	}
+	if c.host == "" {
+		c.host = "github"
+	}
+	c.ghApiKey = c.apiKeys[c.host]
+	c.apiBaseUrl = c.apiUrls[c.host]
+	c.cloneHost = c.cloneHosts[c.host]
 
-	fmt.Println("Success")
+	if !c.isValid() {
+		output.error("Config is missing required fields\n")
+		os.Exit(1)
+	}
+	// Unlike github, gitea is always self-hosted.
+	if c.host == "gitea" && c.apiBaseUrl == "" {
+		output.error("Config is missing gitea_api_url\n")
+		os.Exit(1)
+	}
+}
+
+// createRepoBody is the request body createRepo sends for name.
+func createRepoBody(name string, config *appConfig, opts *appOptions) map[string]any {
+	body := map[string]any{"name": name}
+	if opts.description != "" {
+		body["description"] = opts.description
+	}
+	for k, v := range config.mergeSettings {
+		body[k] = v
+	}
+	if opts.githubInit {
+		body["auto_init"] = true
+		if opts.gitignoreTemplate != "" {
+			body["gitignore_template"] = opts.gitignoreTemplate
+		}
+		if opts.license != "" {
+			body["license_template"] = strings.ToLower(opts.license)
+		}
+	}
+	for k, v := range opts.apiFields {
+		body[k] = v
+	}
+	return body
+}
+
+func createRepo(name string, config *appConfig, opts *appOptions) {
+	body := createRepoBody(name, config, opts)
+	endpoint := createRepoEndpoint(opts.owner, opts.ownerType, config)
+	res := githubRequest(http.MethodPost, endpoint, body, config)
+
+	// 404 is a missing organization, 403 one we may not create repos in.
+	orgDenied := res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusForbidden
+	if opts.ownerFallback && opts.ownerType == "org" && orgDenied {
+		res.Body.Close()
+		fallBackToUser(opts, config, "organization responded with " + res.Status)
+
+		endpoint = createRepoEndpoint(opts.owner, opts.ownerType, config)
+		res = githubRequest(http.MethodPost, endpoint, body, config)
+	}
+
+	if opts.retryAuth && res.StatusCode == http.StatusUnauthorized {
+		res.Body.Close()
+		promptToken(config)
+		res = githubRequest(http.MethodPost, endpoint, body, config)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		exitWithResponse("Failed to create repository", res)
+	}
+}
+
+// verifySsh checks that ssh can authenticate to the git host. ssh -T exits
+// with 1 even on success, so the greeting is what tells the cases apart.
+func verifySsh(config *appConfig) {
+	cmd := exec.CommandContext(runCtx, "ssh", "-T", "-o", "BatchMode=yes", "git@" + config.gitHost())
+	out, _ := cmd.CombinedOutput()
+
+	if !strings.Contains(string(out), "successfully authenticated") {
+		output.error(
+			"SSH access to %s failed, check your ssh keys:\n%s\n",
+			config.gitHost(),
+			strings.TrimSpace(string(out)),
+		)
+		os.Exit(1)
+	}
+}
+
+// cloneCommand is the git clone cloneRepo runs, written for a shell.
+func cloneCommand(owner string, name string, projPath string, config *appConfig) string {
+	return strings.Join([]string{"git", "clone", shellQuote(config.cloneUrl(owner, name)), shellQuote(projPath)}, " ")
+}
+
+// shellQuote single quotes s unless it only holds characters a shell
+// takes literally.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func cloneRepo(owner string, name string, dirName string, config *appConfig) {
+	url := config.cloneUrl(owner, name)
+
+	err := config.retry.do("Clone", func() error {
+		return runGitRetryable(config.projDir, "clone", url, dirName)
+	})
+	iferr("Failed to clone repository: %v\n", err)
+}
+
+func hasChanges(projPath string) bool {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "status", "--porcelain")
+	cmd.Dir = projPath
+	out, err := cmd.Output()
+	iferr("Failed to get repository status: %v\n", err)
+	return len(out) > 0
+}
+
+// commitChanges commits the scaffolded files and reports whether there was
+// anything to commit, --add patterns or .gitignore may leave nothing staged.
+func commitChanges(projPath string, opts *appOptions) bool {
+	committed := false
+	subject := "initial commit"
+	if opts.groupedCommits {
+		committed = commitGrouped(projPath, opts)
+		subject = "chore: initial commit"
+	}
+
+	addArgs := []string{"add", "."}
+	if len(opts.addPatterns) > 0 {
+		addArgs = []string{"add", "--"}
+		for _, p := range opts.addPatterns {
+			addArgs = append(addArgs, ":(glob)" + p)
+		}
+	}
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", addArgs...)
+	cmd.Dir = projPath
+	cmd.Stderr = output.raw()
+	err := cmd.Run()
+	iferr("Failed to add changes: %v\n", err)
+
+	if !hasStagedChanges(projPath) {
+		return committed
+	}
+	commitStaged(projPath, subject, opts)
+	return true
+}
+
+func commitStaged(projPath string, subject string, opts *appOptions) {
+	commitArgs := []string{"commit", "-m", commitMessage(subject, opts)}
+	if opts.signoff {
+		commitArgs = append(commitArgs, "-s")
+	}
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", commitArgs...)
+	cmd.Dir = projPath
+	err := cmd.Run()
+	iferr("Failed to commit changes: %v\n", err)
+}
+
+func hasStagedChanges(projPath string) bool {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "diff", "--cached", "--quiet")
+	cmd.Dir = projPath
+	return cmd.Run() != nil
+}
+
+// commitMessage appends the --co-author trailers to subject.
+func commitMessage(subject string, opts *appOptions) string {
+	if len(opts.coAuthors) == 0 {
+		return subject
+	}
+
+	msg := subject + "\n"
+	for _, a := range opts.coAuthors {
+		msg += "\nCo-authored-by: " + a
+	}
+	return msg
+}
+
+func pushChanges(projPath string, branch string, config *appConfig) {
+	err := config.retry.do("Push", func() error {
+		return runGitRetryable(projPath, "push", "-u", "origin", branch)
+	})
+	iferr("Failed to push changes: %v\n", err)
+}
+
+// initRepo creates the local repository --local-first scaffolds into before
+// the remote exists. Like a clone it refuses a dir that is not empty.
+func initRepo(projPath string, branch string) {
+	if _, err := os.Stat(projPath); err == nil && !isEmptyDir(projPath) {
+		output.error("Project dir already exists and is not empty: %s\n", projPath)
+		os.Exit(1)
+	}
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", "init", "-q", "-b", branch, projPath)
+	err := cmd.Run()
+	iferr("Failed to initialize repository: %v\n", err)
+}
+
+func addOriginRemote(projPath string, owner string, name string, config *appConfig) {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "remote", "add", "origin", config.cloneUrl(owner, name))
+	cmd.Dir = projPath
+	err := cmd.Run()
+	iferr("Failed to add origin remote: %v\n", err)
+}
+
+// renameBranch renames the local branch of a fresh clone so the push does not
+// depend on the user's init.defaultBranch.
+func renameBranch(projPath string, branch string) {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "branch", "-M", branch)
+	cmd.Dir = projPath
+	err := cmd.Run()
+	iferr("Failed to rename branch: %v\n", err)
+}
+
+// gitIdentity returns the user.name and user.email git commits in projPath
+// are made with.
+func gitIdentity(projPath string) (string, string) {
+	get := func(key string) string {
+		cmd := exec.CommandContext(runCtx, "/bin/git", "config", "--get", key)
+		cmd.Dir = projPath
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	return get("user.name"), get("user.email")
+}
+
+// fixIdentity offers to rewrite the commits about to be pushed when the
+// last one was authored with an empty email, one git made up from the host
+// name or one other than the configured user.email. Without a configured
+// identity it asks for one and sets it for the project.
+func fixIdentity(projPath string) {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "log", "-1", "--format=%ae")
+	cmd.Dir = projPath
+	out, err := cmd.Output()
+	iferr("Failed to read commit author: %v\n", err)
+	authorEmail := strings.TrimSpace(string(out))
+
+	name, email := gitIdentity(projPath)
+	made := authorEmail == "" || !strings.Contains(authorEmail, "@") || strings.HasSuffix(authorEmail, ".(none)")
+	if !made && authorEmail == email {
+		return
+	}
+
+	if name == "" || email == "" {
+		output.result("Initial commit was authored as <%s>, git user.name and user.email are not set\n", authorEmail)
+		if !ask("Set them for this project and amend the commit", true) {
+			return
+		}
+		name = promptIdentityField("user.name", name)
+		email = promptIdentityField("user.email", email)
+		for _, kv := range [][2]string{{"user.name", name}, {"user.email", email}} {
+			cmd := exec.CommandContext(runCtx, "/bin/git", "config", kv[0], kv[1])
+			cmd.Dir = projPath
+			err := cmd.Run()
+			iferr("Failed to set git identity: %v\n", err)
+		}
+	} else if !ask(fmt.Sprintf("Initial commit was authored as <%s>, amend it with %s <%s>", authorEmail, name, email), true) {
+		return
+	}
+
+	output.step("Amending commit author...\n")
+	amend := "git commit --amend --no-edit --reset-author"
+	// Only the commits not on the remote yet are rewritten, the one GitHub
+	// made for --github-init or those of a --contribute clone are pushed.
+	unpushed := countCommits(projPath, "HEAD", "--not", "--remotes=origin")
+	base := "--root"
+	if unpushed < countCommits(projPath, "HEAD") {
+		base = fmt.Sprintf("HEAD~%d", unpushed)
+	}
+
+	cmd = exec.CommandContext(runCtx, "/bin/git", "rebase", "-q", "--exec", amend, base)
+	cmd.Dir = projPath
+	err = cmd.Run()
+	iferr("Failed to amend commit author: %v\n", err)
+}
+
+func countCommits(projPath string, revs ...string) int {
+	cmd := exec.CommandContext(runCtx, "/bin/git", append([]string{"rev-list", "--count"}, revs...)...)
+	cmd.Dir = projPath
+	out, err := cmd.Output()
+	iferr("Failed to count commits: %v\n", err)
+
+	n, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	iferr("Failed to count commits: %v\n", err)
+	return n
+}
+
+func promptIdentityField(key string, current string) string {
+	if current != "" {
+		return current
+	}
+	for {
+		output.result("%s: ", key)
+		v, ok := readLine()
+		if v != "" {
+			return v
+		}
+		if !ok {
+			output.error("No %s given\n", key)
+			os.Exit(1)
+		}
+	}
+}
+
+// requireGitIdentity is gitIdentity for files that cannot be written
+// without it, option names the flag asking for them.
+func requireGitIdentity(projPath string, option string) (string, string) {
+	name, email := gitIdentity(projPath)
+	if name == "" || email == "" {
+		output.error("%s requires git user.name and user.email to be set\n", option)
+		os.Exit(1)
+	}
+	return name, email
+}
+
+// createDirs creates dirs under projPath, adding a .gitkeep to the ones left
+// empty so git tracks them.
+func createDirs(projPath string, dirs []string) {
+	for _, d := range dirs {
+		p := filepath.Join(projPath, d)
+		err := os.MkdirAll(p, 0755)
+		iferr("Failed to create dir: %v\n", err)
+
+		entries, err := os.ReadDir(p)
+		iferr("Failed to read dir: %v\n", err)
+		if len(entries) == 0 {
+			f := createFile(filepath.Join(p, ".gitkeep"))
+			f.Close()
+		}
+	}
+}
+
+func createMailmap(projPath string) {
+	name, email := requireGitIdentity(projPath, "--mailmap")
+
+	f := createFile(filepath.Join(projPath, ".mailmap"))
+	fmt.Fprintf(f, "%s <%s>\n", name, email)
+	f.Close()
+}
+
+func createAuthors(projName string, projPath string) {
+	name, email := requireGitIdentity(projPath, "--authors")
+
+	f := createFile(filepath.Join(projPath, "AUTHORS"))
+	fmt.Fprintf(f, "# Authors of %s\n\n%s <%s>\n", buildTitle(projName), name, email)
+	f.Close()
+}
+
+// versionPattern loosely matches version numbers like 1, 0.1.0, v2.0 or
+// 1.0.0-rc.1+build.5.
+var versionPattern = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*([-+][0-9A-Za-z.+-]+)?$`)
+
+// createChangelog seeds CHANGELOG.md in the Keep a Changelog format.
+func createChangelog(projName string, projPath string) {
+	f := createFile(filepath.Join(projPath, "CHANGELOG.md"))
+	fmt.Fprintf(
+		f,
+		"# Changelog of %s\n\n" +
+		"All notable changes to this project will be documented in this file.\n\n" +
+		"The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),\n" +
+		"and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).\n\n" +
+		"## [Unreleased]\n",
+		buildTitle(projName),
+	)
+	f.Close()
+}
+
+func currentBranch(projPath string) string {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "symbolic-ref", "--short", "HEAD")
+	cmd.Dir = projPath
+	out, err := cmd.Output()
+	iferr("Failed to get current branch: %v\n", err)
+	return strings.TrimSpace(string(out))
+}
+
+// originUrl returns the url of the origin remote of the repository at
+// projPath, or "" without one. projPath has to be a repository, git would
+// look at its parents otherwise.
+func originUrl(projPath string) string {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "config", "remote.origin.url")
+	cmd.Dir = projPath
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// isAheadOfOrigin reports whether projPath is a clone left behind by an
+// earlier run whose commits never made it to origin, e.g. because push
+// failed on auth. Without origin nothing is ahead of it, every commit would
+// count otherwise.
+func isAheadOfOrigin(projPath string) bool {
+	if _, err := os.Stat(filepath.Join(projPath, ".git")); err != nil || originUrl(projPath) == "" {
+		return false
+	}
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", "rev-list", "--count", "HEAD", "--not", "--remotes=origin")
+	cmd.Dir = projPath
+	out, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	return err == nil && n > 0
+}
+
+// isUnpublished reports whether projPath is a repository a --local-first
+// run committed to but that never got origin, because creating the remote
+// failed.
+func isUnpublished(projPath string) bool {
+	if _, err := os.Stat(filepath.Join(projPath, ".git")); err != nil || originUrl(projPath) != "" {
+		return false
+	}
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", "rev-parse", "-q", "--verify", "HEAD")
+	cmd.Dir = projPath
+	return cmd.Run() == nil
+}
+
+func createFile(name string) *textFile {
+	f, err := os.Create(name)
+	iferr("Failed to create file: %v\n", err)
+
+	err = f.Chmod(0644)
+	iferr("Failed to change file mode: %v\n", err)
+
+	return &textFile{f: f}
+}
+
+func buildTitle(s string) string {
+	title := strings.Builder{}
+
+	for i, word := range strings.Split(s, "-") {
+		if i > 0 {
+			title.WriteString(" ")
+		}
+		capitalized := strings.ToUpper(string(word[0])) + word[1:]
+		title.WriteString(capitalized)
+	}
+
+	return title.String()
+}
+
+// gitignoreHeader notes where a .gitignore made from a github template came
+// from. gitignore_header = false in the config or --no-gitignore-header
+// leave it out.
+func gitignoreHeader(template string) string {
+	return fmt.Sprintf("# Generated by create-project from the %s gitignore template\n\n", template)
+}
+
+func createGitignore(projPath string, content string) {
+	f := createFile(filepath.Join(projPath, ".gitignore"))
+	f.WriteString(content)
+	f.Close()
+}
+
+func createReadmeGitignore(
+	title string,
+	projPath string,
+	description string,
+	gitignoreContent string,
+) {
+	createGitignore(projPath, gitignoreContent)
+
+	readme := createFile(filepath.Join(projPath, "README.md"))
+	readme.WriteString("# " + title)
+	if description != "" {
+		readme.WriteString("\n\n" + description + "\n")
+	}
+	readme.Close()
+}
+
+// installGitHooks commits hooks to .githooks and points core.hooksPath at
+// it, since .git/hooks itself is never tracked.
+func installGitHooks(projPath string, templates []string) {
+	hooksDir := filepath.Join(projPath, ".githooks")
+	err := os.MkdirAll(hooksDir, 0755)
+	iferr("Failed to create hooks folder: %v\n", err)
+
+	hook := genericPreCommitHook
+	if slices.Contains(templates, "go") {
+		hook = goPreCommitHook
+	}
+
+	f := createFile(filepath.Join(hooksDir, "pre-commit"))
+	f.WriteString(hook)
+	err = f.Chmod(0755)
+	iferr("Failed to change file mode: %v\n", err)
+	f.Close()
+
+	setHooksPath(projPath)
+}
+
+func setHooksPath(projPath string) {
+	cmd := exec.CommandContext(runCtx, "/bin/git", "config", "core.hooksPath", ".githooks")
+	cmd.Dir = projPath
+	err := cmd.Run()
+	iferr("Failed to set hooks path: %v\n", err)
+}
+
+// tokenScopeHint names the token permissions create-project needs, for
+// classic and for fine-grained tokens.
+const tokenScopeHint = "The github api key needs the repo scope (classic token) or\n" +
+	"administration: write and contents: write (fine-grained token)"
+
+func generateConfig() {
+	configPath := getConfigPath()
+
+	err := os.MkdirAll(filepath.Dir(configPath), 0700)
+	iferr("failed to create config folder: %v\n", err)
+
+	f := createFile(configPath)
+	defer f.Close()
+
+	f.WriteString("# " + strings.ReplaceAll(tokenScopeHint, "\n", "\n# ") + "\n")
+	f.WriteString(
+		"gh_apikey    = github api key\n" +
+		"gh_username  = github username\n" +
+		"projects_dir = /absolute/path/to/dir\n",
+	)
+	output.step("Config created %v\n", configPath)
+	output.step("%s\n", tokenScopeHint)
+}
+
+// stdin is shared by every prompt, a scanner per prompt would lose input
+// buffered by the previous one.
+var stdin = bufio.NewScanner(os.Stdin)
+
+// runCtx bounds every command and request of the run, --timeout makes it
+// expire once the whole run took too long.
+var runCtx = context.Background()
+
+// readLine reads a trimmed line from stdin and reports false on EOF.
+func readLine() (string, bool) {
+	if !stdin.Scan() {
+		iferr("Failed to scan user input: %v\n", stdin.Err())
+		return "", false
+	}
+	return strings.TrimSpace(stdin.Text()), true
+}
+
+// ask reports whether the user agrees. Empty input picks defaultYes, which
+// is shown capitalized in the prompt.
+func ask(prompt string, defaultYes bool) bool {
+	choices := "y/N"
+	if defaultYes {
+		choices = "Y/n"
+	}
+	output.result("%s (%s)\n", prompt, choices)
+
+	line, _ := readLine()
+	input := strings.ToLower(line)
+	if input == "" {
+		return defaultYes
+	}
+	return input == "y" || input == "yes"
+}
+
+// confirm exits unless the user agrees.
+func confirm(prompt string, defaultYes bool) {
+	if !ask(prompt, defaultYes) {
+		os.Exit(0)
+	}
+}
+
+// networkSteps lists what the chosen options would need network access for.
+func networkSteps(opts *appOptions, config *appConfig) []string {
+	steps := []string{}
+
+	if opts.owner != "" && opts.owner != config.ghUsername && opts.ownerType == "" {
+		steps = append(steps, "owner type detection")
+	}
+	if opts.autoSuffix {
+		steps = append(steps, "name availability")
+	}
+	if opts.dryRun {
+		if opts.validateRemote {
+			steps = append(steps, "remote validation")
+		}
+		return steps
+	}
+	if opts.clean {
+		return append(steps, "repository cleanup")
+	}
+	if opts.checkName {
+		return append(steps, "name check")
+	}
+	if opts.printCloneCommand {
+		return steps
+	}
+	if _, ok := cachedLicense(opts.license); fetchesLicense(opts) && !ok {
+		steps = append(steps, "license template")
+	}
+	if _, ok := cachedGitignore(opts.gitignoreTemplate); opts.gitignoreTemplate != "" && !opts.githubInit && !ok {
+		steps = append(steps, "gitignore template")
+	}
+	if opts.templateSource() != "" {
+		if _, err := os.Stat(opts.templateSource()); err != nil {
+			steps = append(steps, "template repository")
+		}
+	}
+	if opts.emitScript != "" {
+		return steps
+	}
+	if opts.verifySsh {
+		steps = append(steps, "ssh verification")
+	}
+	if opts.fork != "" {
+		steps = append(steps, "fork")
+	} else if opts.contribute != "" {
+		steps = append(steps, "pull request")
+	} else if !opts.reinitExisting {
+		steps = append(steps, "repository creation")
+	}
+	if opts.pruneLabels {
+		steps = append(steps, "label pruning")
+	}
+	if opts.asTemplate {
+		steps = append(steps, "template flag")
+	}
+	if opts.ruleset != "" {
+		steps = append(steps, "ruleset creation")
+	}
+	if len(opts.variables) > 0 {
+		steps = append(steps, "actions variables")
+	}
+	if len(opts.secrets) > 0 {
+		steps = append(steps, "actions secrets")
+	}
+	if opts.deployKey != "" {
+		steps = append(steps, "deploy key")
+	}
+	if !opts.reinitExisting {
+		steps = append(steps, "clone")
+	}
+	steps = append(steps, "push")
+	if opts.notifyWebhook != "" {
+		steps = append(steps, "notify webhook")
+	}
+
+	return steps
+}
+
+func checkNoNetwork(opts *appOptions, config *appConfig) {
+	steps := networkSteps(opts, config)
+	if len(steps) > 0 {
+		output.error("--no-network is set but the run needs network for: %s\n", strings.Join(steps, ", "))
+		os.Exit(1)
+	}
+}
+
+// checkDirPermissions warns when other users could write to projects dir or
+// one of its parents and so tamper with the projects cloned there. Parents
+// with the sticky bit, like /tmp, only let others add entries, so just the
+// projects dir itself is held to that.
+func checkDirPermissions(projDir string) {
+	dir, err := filepath.Abs(projDir)
+	iferr("Failed to resolve projects dir: %v\n", err)
+
+	for p := dir; ; p = filepath.Dir(p) {
+		info, err := os.Stat(p)
+		if err == nil {
+			mode := info.Mode()
+			worldWritable := mode.Perm() & 0002 != 0
+			if worldWritable && (p == dir || mode & os.ModeSticky == 0) {
+				output.warn("%s is world-writable, projects in %s could be tampered with\n", p, dir)
+			}
+		}
+
+		if p == filepath.Dir(p) {
+			break
+		}
+	}
+}
+
+// promptToken asks for a token to replace the one github rejected and
+// offers to write it to the config file, so the next run uses it too.
+func promptToken(config *appConfig) {
+	output.result("GitHub rejected the configured token, paste a new one:\n")
+	token, _ := readLine()
+	if token == "" {
+		output.error("No token given\n")
+		os.Exit(1)
+	}
+	config.ghApiKey = token
+
+	if !ask(fmt.Sprintf("Save token to %s", getConfigPath()), false) {
+		return
+	}
+
+	err := saveConfigValue(apiKeyFields[config.host], token)
+	if err != nil {
+		output.warn("failed to save token: %v\n", err)
+	}
+}
+
+// saveConfigValue sets key to value in the config file, replacing the line
+// that set it before and keeping every other line as it was.
+func saveConfigValue(key string, value string) error {
+	configPath := getConfigPath()
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	found := false
+	for i, line := range lines {
+		k, _, ok := strings.Cut(stripBom(line), "=")
+		if ok && strings.Trim(k, " ") == key {
+			lines[i] = key + " = " + value
+			found = true
+		}
+	}
+	if !found {
+		if lines[len(lines) - 1] == "" {
+			lines = lines[:len(lines) - 1]
+		}
+		lines = append(lines, key + " = " + value, "")
+	}
+
+	return os.WriteFile(configPath, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+}
+
+func fallBackToUser(opts *appOptions, config *appConfig, reason string) {
+	output.warn(
+		"cannot create repository under %s, %s, creating it under %s\n",
+		opts.owner,
+		reason,
+		config.ghUsername,
+	)
+	opts.owner = config.ghUsername
+	opts.ownerType = "user"
+}
+
+// resolveOwner defaults the owner to the configured user and works out
+// which endpoint creates repositories for it.
+func resolveOwner(opts *appOptions, config *appConfig) {
+	if opts.owner == "" {
+		opts.owner = config.ghUsername
+	}
+
+	// --contribute creates nothing, the owner is the one of the repository
+	// contributed to and may well be another user.
+	if opts.contribute != "" {
+		return
+	}
+
+	if opts.ownerType == "" {
+		if opts.owner == config.ghUsername {
+			opts.ownerType = "user"
+		} else {
+			opts.ownerType = detectOwnerType(opts.owner, config)
+		}
+	}
+
+	if opts.ownerType == "" {
+		if !opts.ownerFallback {
+			output.error("Unknown owner: %s\n", opts.owner)
+			os.Exit(1)
+		}
+		fallBackToUser(opts, config, "it does not exist")
+	}
+
+	if opts.ownerType == "user" && opts.owner != config.ghUsername {
+		output.error("Cannot create repository for another user: %s\n", opts.owner)
+		os.Exit(1)
+	}
+}
+
+// projectAssets holds everything fetched from the api before the repository
+// is created, so a bad license or template name does not leave an orphan repo.
+type projectAssets struct {
+	license githubLicense
+	gitignore string
+	templateGitDir string
+	templateVars map[string]string
+}
+
+func (a *projectAssets) cleanup() {
+	if a.templateGitDir != "" {
+		os.RemoveAll(a.templateGitDir)
+	}
+}
+
+// fetchesLicense reports whether the license template is fetched. With
+// --github-init GitHub writes LICENSE itself, only the README.md License
+// section still needs the license's name.
+func fetchesLicense(opts *appOptions) bool {
+	return opts.license != "" && (!opts.githubInit || opts.readmeLicenseSection)
+}
+
+func fetchAssets(config *appConfig, opts *appOptions) projectAssets {
+	assets := projectAssets{}
+
+	if fetchesLicense(opts) {
+		if license, ok := cachedLicense(opts.license); ok {
+			assets.license = license
+		} else {
+			output.step("Fetching license template...\n")
+			assets.license = fetchLicense(opts.license, config)
+		}
+	}
+
+	if opts.gitignoreTemplate != "" && !opts.githubInit {
+		if gitignore, ok := cachedGitignore(opts.gitignoreTemplate); ok {
+			assets.gitignore = gitignore
+		} else {
+			output.step("Fetching gitignore template...\n")
+			assets.gitignore = fetchGitignoreTemplate(opts.gitignoreTemplate, config)
+		}
+		if config.gitignoreHeader {
+			assets.gitignore = gitignoreHeader(opts.gitignoreTemplate) + assets.gitignore
+		}
+	}
+
+	if opts.templateSource() != "" {
+		output.step("Fetching template repository...\n")
+		dir, manifest := fetchTemplateChain(opts.templateSource(), config)
+		assets.templateGitDir = dir
+		given := map[string]string{}
+		if opts.envFile != "" {
+			given = loadEnvFile(opts.envFile)
+		}
+		for k, v := range opts.vars {
+			given[k] = v
+		}
+		assets.templateVars = collectTemplateVars(manifest, given)
+
+		// The template's description stands in for a missing --description,
+		// with {{lang}} naming the language template it is combined with.
+		if opts.description == "" && manifest.Description != "" {
+			vars := map[string]string{"lang": primaryLanguage(opts.templates)}
+			for k, v := range assets.templateVars {
+				vars[k] = v
+			}
+			for k, v := range templateVars(opts.projName, opts.owner) {
+				vars[k] = v
+			}
+			description := string(substituteVars([]byte(manifest.Description), vars))
+			opts.description = truncateDescription(expandShortcodes(description), opts.descriptionMaxLen)
+		}
+	}
+
+	return assets
+}
+
+func scaffoldProject(
+	projName string,
+	projPath string,
+	assets *projectAssets,
+	config *appConfig,
+	opts *appOptions,
+) {
+	switch {
+	case opts.githubInit:
+	case opts.noDefaultFiles:
+		if opts.gitignoreTemplate != "" {
+			output.step("Creating .gitignore...\n")
+			createGitignore(projPath, assets.gitignore)
+		}
+	default:
+		output.step("Creating README.md and .gitignore...\n")
+		createReadmeGitignore(opts.projectTitle(), projPath, opts.description, assets.gitignore)
+	}
+
+	if opts.license != "" {
+		if !opts.githubInit {
+			output.step("Creating LICENSE...\n")
+			createLicense(projPath, assets.license, config.ghUsername)
+		}
+
+		if opts.notice {
+			createNotice(projName, projPath, config.ghUsername)
+		}
+
+		if opts.readmeLicenseSection {
+			appendReadmeLicense(projPath, assets.license)
+		}
+	}
+
+	for _, t := range opts.templates {
+		output.step("Applying %s template...\n", t)
+		applyTemplate(t, projName, projPath, config, opts)
+	}
+
+	if opts.format {
+		for _, t := range opts.templates {
+			formatTemplate(t, projPath)
+		}
+	}
+
+	if assets.templateGitDir != "" {
+		output.step("Copying template repository files...\n")
+		vars := map[string]string{}
+		for k, v := range assets.templateVars {
+			vars[k] = v
+		}
+		for k, v := range templateVars(projName, opts.owner) {
+			vars[k] = v
+		}
+		copyTemplateDir(assets.templateGitDir, projPath, vars, loadTemplateIgnore(assets.templateGitDir))
+	}
+
+	if opts.makefile {
+		output.step("Creating Makefile...\n")
+		createMakefile(projName, projPath, opts)
+	}
+
+	if opts.vscode {
+		output.step("Creating .vscode settings...\n")
+		createVscodeSettings(projPath, opts.templates)
+	}
+
+	if len(opts.dirs) > 0 {
+		output.step("Creating dirs...\n")
+		createDirs(projPath, opts.dirs)
+	}
+
+	if opts.mailmap {
+		output.step("Creating .mailmap...\n")
+		createMailmap(projPath)
+	}
+
+	if opts.authors {
+		output.step("Creating AUTHORS...\n")
+		createAuthors(projName, projPath)
+	}
+
+	if opts.changelog {
+		output.step("Creating CHANGELOG.md...\n")
+		createChangelog(projName, projPath)
+	}
+
+	if opts.versionFile != "" {
+		output.step("Creating VERSION...\n")
+		f := createFile(filepath.Join(projPath, "VERSION"))
+		f.WriteString(opts.versionFile + "\n")
+		f.Close()
+	}
+
+	if len(opts.funding) > 0 {
+		output.step("Creating .github/FUNDING.yml...\n")
+		createFunding(projPath, opts.funding)
+	}
+
+	if opts.gitHooks {
+		output.step("Installing git hooks...\n")
+		installGitHooks(projPath, opts.templates)
+	}
+}
+
+func printSummary(opts *appOptions, format string, a ...any) {
+	if opts.summaryOnly {
+		output.result(format + "\n", a...)
+	}
+}
+
+// editorCommand builds the command opening projPath in the user's editor,
+// run from inside the project. The editor may carry its own arguments,
+// e.g. "code --wait".
+func editorCommand(projPath string, config *appConfig) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = config.editor
+	}
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return nil
+	}
+
+	// The editor outlives --timeout, it only opens once the project is done.
+	cmd := exec.Command(args[0], append(args[1:], ".")...)
+	cmd.Dir = projPath
+	return cmd
+}
+
+func openEditor(projPath string, config *appConfig) {
+	cmd := editorCommand(projPath, config)
+	if cmd == nil {
+		output.warn("--edit needs $VISUAL, $EDITOR or editor in config\n")
+		return
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = output.stdout
+	cmd.Stderr = output.stderr
+	err := cmd.Run()
+	iferr("Failed to run editor: %v\n", err)
+}
+
+// createRemote creates the GitHub repository, or reuses one created within
+// --since, and applies the settings made through the API.
+func createRemote(projName string, metrics *phaseMetrics, config *appConfig, opts *appOptions) {
+	metrics.measure("create", func() {
+		if opts.since > 0 && repoCreatedWithin(opts.owner, projName, opts.since, config) {
+			output.step("Repository %s/%s was created within %v, reusing it\n", opts.owner, projName, opts.since)
+		} else {
+			output.step("Creating GitHub repository...\n")
+			createRepo(projName, config, opts)
+		}
+	})
+
+	if opts.pruneLabels {
+		output.step("Deleting default labels...\n")
+		pruneLabels(opts.owner, projName, config)
+	}
+
+	if opts.asTemplate {
+		output.step("Marking repository as template...\n")
+		markAsTemplate(opts.owner, projName, config)
+	}
+
+	if opts.ruleset != "" {
+		output.step("Creating ruleset from %s...\n", opts.ruleset)
+		createRuleset(opts.owner, projName, opts.rulesetBody, config)
+	}
+
+	if len(opts.variables) > 0 {
+		output.step("Setting actions variables...\n")
+		for _, v := range opts.variables {
+			createActionsVariable(opts.owner, projName, v[0], v[1], config)
+		}
+	}
+
+	if len(opts.secrets) > 0 {
+		output.step("Setting actions secrets...\n")
+		key := fetchSecretsPublicKey(opts.owner, projName, config)
+		for _, s := range opts.secrets {
+			createActionsSecret(opts.owner, projName, s[0], s[1], key, config)
+		}
+	}
+
+	if opts.deployKey != "" {
+		output.step("Adding deploy key %s...\n", opts.deployKey)
+		key := generateDeployKey(opts.deployKey, fmt.Sprintf("%s/%s", opts.owner, projName))
+		addDeployKey(opts.owner, projName, key, !opts.deployKeyWrite, config)
+	}
+}
+
+func main() {
+	opts := parseArgs(expandPresets(applyConfigFlag(os.Args[1:])))
+	output.setup(&opts)
+
+	// A script, the json plan of a dry run or a clone command printed to
+	// stdout must not be mixed with progress output.
+	output.quiet = opts.summaryOnly || opts.emitScript == "-" || opts.dryRun && opts.planFormat == "json" || opts.printCloneCommand
+
+	if opts.timeout > 0 {
+		ctx, cancel := context.WithTimeoutCause(context.Background(), opts.timeout, fmt.Errorf("run timed out after %v", opts.timeout))
+		defer cancel()
+		runCtx = ctx
+	}
+
+	output.step("Loading config file...\n")
+	config := appConfig{host: opts.host}
+	config.load()
+
+	if opts.here {
+		cwd, err := os.Getwd()
+		iferr("Failed to get current directory: %v\n", err)
+		config.projDir = cwd
+	}
+	if config.projDir == "" && !opts.prefetchTemplates {
+		output.error("Config is missing projects_dir, set it or use --here\n")
+		os.Exit(1)
+	}
+	if opts.dirPermissionsCheck {
+		checkDirPermissions(config.projDir)
+	}
+
+	for k, v := range opts.mergeSettings {
+		config.mergeSettings[k] = v
+	}
+	if opts.maxRetries >= 0 {
+		config.retry.perOperation = opts.maxRetries
+	}
+	if opts.maxRetriesTotal >= 0 {
+		config.retry.remaining = opts.maxRetriesTotal
+	}
+	if opts.retryOn != nil {
+		config.retry.retryOn = opts.retryOn
+	}
+	if opts.retryJitter {
+		config.retry.jitter = true
+	}
+	if opts.noGitignoreHeader {
+		config.gitignoreHeader = false
+	}
+	if opts.tlsMinVersion != 0 {
+		config.tlsMinVersion = opts.tlsMinVersion
+	}
+	config.trace = opts.trace
+	config.readOnly = opts.dryRun || opts.emitScript != ""
+	fileStyle = config.textStyle
+
+	if opts.prefetchTemplates {
+		prefetchTemplates(&config)
+		return
+	}
+
+	if opts.noNetwork {
+		checkNoNetwork(&opts, &config)
+	}
+
+	resolveTemplate(&opts, &config)
+	resolveOwner(&opts, &config)
+
+	if len(opts.projNames) > 1 {
+		os.Exit(printRunSummary(runMany(&opts, &config)))
+	}
+
+	if opts.autoSuffix {
+		opts.projName = availableName(opts.owner, opts.projName, &config)
+	}
+
+	projName := opts.projName
+	dirName := transformDirName(projName, opts.dirTransforms)
+	projPath := filepath.Join(config.projDir, dirName)
+
+	if opts.descriptionFromGit && opts.description == "" {
+		opts.description = truncateDescription(expandShortcodes(readExistingDescription(projPath)), opts.descriptionMaxLen)
+	}
+
+	if opts.dryRun {
+		format := opts.planFormat
+		if format == "" {
+			format = "text"
+		}
+		printPlan(buildPlan(projPath, &config, &opts), format)
+		if opts.validateRemote {
+			validateRemote(projName, &config, &opts)
+		}
+		return
+	}
+
+	if opts.emitScript != "" {
+		emitScript(projName, projPath, &config, &opts)
+		return
+	}
+
+	if opts.clean {
+		cleanProject(projName, projPath, &config, &opts)
+		return
+	}
+
+	if opts.checkName {
+		if repoExists(opts.owner, projName, &config) {
+			output.result("%s/%s is taken\n", opts.owner, projName)
+			os.Exit(2)
+		}
+		output.result("%s/%s is available\n", opts.owner, projName)
+		return
+	}
+
+	if opts.printCloneCommand {
+		output.result("%s\n", cloneCommand(opts.owner, projName, projPath, &config))
+		return
+	}
+
+	if opts.reinitExisting {
+		confirm(fmt.Sprintf("Add missing files to project %v", projPath), config.confirmDefault)
+		assets := fetchAssets(&config, &opts)
+		defer assets.cleanup()
+		reinitProject(projName, projPath, &assets, &config, &opts)
+		return
+	}
+
+	if opts.contribute != "" {
+		contributeProject(projPath, dirName, &config, &opts)
+		return
+	}
+
+	if opts.fork != "" {
+		confirm(fmt.Sprintf("Fork %s into %v", opts.fork, projPath), config.confirmDefault)
+
+		if opts.verifySsh {
+			output.step("Verifying SSH access...\n")
+			verifySsh(&config)
+		}
+
+		output.step("Forking %s...\n", opts.fork)
+		forkName := forkRepo(opts.fork, projName, &config, &opts)
+
+		output.step("Cloning fork into %s...\n", projPath)
+		cloneRepo(opts.owner, forkName, dirName, &config)
+		addUpstreamRemote(projPath, opts.fork, &config)
+
+		output.step("Success\n")
+		printSummary(&opts, "forked %s to %s/%s at %s", opts.fork, opts.owner, forkName, projPath)
+
+		result := newProjectResult(opts.owner, forkName, projPath, &config)
+		appendToRegistry(result)
+		writeResultFile(result)
+		notify(&opts, result)
+
+		if opts.edit {
+			openEditor(projPath, &config)
+		}
+		return
+	}
+
+	// Only a clone of the repository this run would create is resumed, any
+	// other checkout at projPath is left to the flows above.
+	if isAheadOfOrigin(projPath) && originUrl(projPath) == config.cloneUrl(opts.owner, projName) {
+		output.step("Found unpushed commits in %s, retrying push...\n", projPath)
+		confirm(fmt.Sprintf("Push unpushed commits in %v", projPath), config.confirmDefault)
+		branch := currentBranch(projPath)
+		pushChanges(projPath, branch, &config)
+		output.step("Success\n")
+		printSummary(&opts, "pushed %s/%s at %s on %s", opts.owner, projName, projPath, branch)
+		return
+	}
+
+	if opts.localFirst && isUnpublished(projPath) {
+		output.step("Found local commits in %s without origin, resuming...\n", projPath)
+		confirm(fmt.Sprintf("Publish local commits in %v", projPath), config.confirmDefault)
+		metrics := phaseMetrics{enabled: opts.metrics}
+		createRemote(projName, &metrics, &config, &opts)
+		addOriginRemote(projPath, opts.owner, projName, &config)
+		branch := currentBranch(projPath)
+		pushChanges(projPath, branch, &config)
+		output.step("Success\n")
+		printSummary(&opts, "created %s/%s at %s on %s", opts.owner, projName, projPath, branch)
+
+		result := newProjectResult(opts.owner, projName, projPath, &config)
+		appendToRegistry(result)
+		writeResultFile(result)
+		notify(&opts, result)
+		return
+	}
+
+	if opts.planFormat != "" {
+		printPlan(buildPlan(projPath, &config, &opts), opts.planFormat)
+	}
+
+	confirm(fmt.Sprintf("Create project %v", projPath), config.confirmDefault)
+
+	metrics := phaseMetrics{enabled: opts.metrics}
+
+	if opts.verifySsh {
+		output.step("Verifying SSH access...\n")
+		metrics.measure("auth check", func() {
+			verifySsh(&config)
+		})
+	}
+
+	var assets projectAssets
+	metrics.measure("fetch", func() {
+		assets = fetchAssets(&config, &opts)
+	})
+	defer assets.cleanup()
+
+	// A github-init clone already tracks the remote default branch, keep it
+	// unless a branch was asked for explicitly.
+	branch := opts.branch
+	if branch == "" && !opts.githubInit {
+		branch = "main"
+	}
+
+	if opts.localFirst {
+		output.step("Initializing repository in %s...\n", projPath)
+		initRepo(projPath, branch)
+	} else {
+		createRemote(projName, &metrics, &config, &opts)
+
+		output.step("Cloning repository into %s...\n", projPath)
+		metrics.measure("clone", func() {
+			cloneRepo(opts.owner, projName, dirName, &config)
+		})
+
+		if branch != "" {
+			renameBranch(projPath, branch)
+		} else {
+			branch = currentBranch(projPath)
+		}
+	}
+
+	metrics.measure("scaffold", func() {
+		scaffoldProject(projName, projPath, &assets, &config, &opts)
+	})
+
+	if opts.githubInit && !hasChanges(projPath) {
+		output.step("Nothing to commit, repository was initialized by GitHub\n")
+	} else {
+		output.step("Committing changes to the repository...\n")
+		committed := false
+		metrics.measure("commit", func() {
+			committed = commitChanges(projPath, &opts)
+		})
+		if committed && opts.fixIdentity {
+			fixIdentity(projPath)
+		}
+
+		if opts.localFirst {
+			// Creating the remote now would leave it empty, the orphan
+			// --local-first is there to avoid.
+			if !committed {
+				output.error("Nothing to commit, not creating repository %s/%s\n", opts.owner, projName)
+				os.Exit(1)
+			}
+			createRemote(projName, &metrics, &config, &opts)
+			addOriginRemote(projPath, opts.owner, projName, &config)
+		}
+
+		switch {
+		case committed:
+			metrics.measure("push", func() {
+				pushChanges(projPath, branch, &config)
+			})
+		case opts.deleteOnEmptyPush:
+			output.step("Nothing to commit, deleting repository %s/%s...\n", opts.owner, projName)
+			deleteRepo(opts.owner, projName, &config)
+			output.error("Nothing to commit, deleted empty repository %s/%s\n", opts.owner, projName)
+			os.Exit(1)
+		default:
+			output.warn("nothing to commit, skipping push, %s/%s is left empty\n", opts.owner, projName)
+		}
+	}
+
+	output.step("Success\n")
+	metrics.print()
+	printSummary(&opts, "created %s/%s at %s on %s", opts.owner, projName, projPath, branch)
+
+	result := newProjectResult(opts.owner, projName, projPath, &config)
+	appendToRegistry(result)
+	writeResultFile(result)
+	notify(&opts, result)
+
+	if opts.edit {
+		openEditor(projPath, &config)
+	}
 }
@@ -0,0 +1,1534 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runMainEnv makes the test binary run main instead of the tests, so a test
+// can run the whole program the way a user would, including the runs
+// runMany starts by re-executing it.
+const runMainEnv = "CREATE_PROJECT_RUN_MAIN"
+
+// exitEnv names the test whose function expectExit runs in a child process.
+const exitEnv = "CREATE_PROJECT_EXIT_TEST"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// unsetenv unsets key for the duration of the test.
+func unsetenv(t *testing.T, key string) {
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+// apiRequest is a request the mock api received.
+type apiRequest struct {
+	Method string
+	Path string
+	Header http.Header
+	Body []byte
+}
+
+// json decodes the request body into a map.
+func (r apiRequest) json(t *testing.T) map[string]any {
+	t.Helper()
+	body := map[string]any{}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		t.Fatalf("%s %s: invalid json body %q: %v", r.Method, r.Path, r.Body, err)
+	}
+	return body
+}
+
+// mockApi records every request and answers the ones routed with handle,
+// anything else gets a json 404 like the real api.
+type mockApi struct {
+	*httptest.Server
+	mux *http.ServeMux
+	mu sync.Mutex
+	requests []apiRequest
+}
+
+func newMockApi(t *testing.T) *mockApi {
+	api := &mockApi{mux: http.NewServeMux()}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		api.mu.Lock()
+		api.requests = append(api.requests, apiRequest{r.Method, r.URL.RequestURI(), r.Header.Clone(), body})
+		api.mu.Unlock()
+
+		if _, pattern := api.mux.Handler(r); pattern == "" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message": "Not Found"}`)
+			return
+		}
+		api.mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+// handle answers requests matching the http.ServeMux pattern with status
+// and body.
+func (a *mockApi) handle(pattern string, status int, body string) {
+	a.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	})
+}
+
+func (a *mockApi) handleFunc(pattern string, handler http.HandlerFunc) {
+	a.mux.HandleFunc(pattern, handler)
+}
+
+// received returns the requests made with method to a path matching the
+// regexp path.
+func (a *mockApi) received(method string, path string) []apiRequest {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	re := regexp.MustCompile("^" + path + "$")
+	matching := []apiRequest{}
+	for _, r := range a.requests {
+		if r.Method == method && re.MatchString(r.Path) {
+			matching = append(matching, r)
+		}
+	}
+	return matching
+}
+
+// testEnv is an isolated environment for a run: a config file pointing at
+// a mock api, a projects dir and a git that has an identity and clones
+// git@github.com:OWNER/NAME.git from bare repositories under remotes.
+type testEnv struct {
+	dir string
+	configPath string
+	projDir string
+	remotes string
+	api *mockApi
+}
+
+const (
+	testUser = "octocat"
+	testToken = "test-token"
+)
+
+// newTestEnv sets up a testEnv whose config holds config on top of the
+// required fields. The api creates the bare repository of every repository
+// created through it.
+func newTestEnv(t *testing.T, config ...string) *testEnv {
+	dir := t.TempDir()
+	env := &testEnv{
+		dir: dir,
+		configPath: filepath.Join(dir, "config", "create-project", "config"),
+		projDir: filepath.Join(dir, "projects"),
+		remotes: filepath.Join(dir, "remotes"),
+		api: newMockApi(t),
+	}
+
+	for _, d := range []string{filepath.Dir(env.configPath), env.projDir, env.remotes, filepath.Join(dir, "home")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv(configEnv, env.configPath)
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, key := range []string{
+		"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL",
+		"GIT_DIR", "GIT_WORK_TREE", "VISUAL", "EDITOR", "NO_COLOR",
+	} {
+		unsetenv(t, key)
+	}
+
+	env.writeGitConfig(t, true)
+
+	lines := []string{
+		"gh_username = " + testUser,
+		"gh_apikey = " + testToken,
+		"projects_dir = " + env.projDir,
+		"api_url = " + env.api.URL,
+	}
+	env.writeConfig(t, append(lines, config...)...)
+
+	env.api.handleFunc("POST /user/repos", func(w http.ResponseWriter, r *http.Request) {
+		env.createRepo(t, w, r, testUser)
+	})
+	env.api.handleFunc("POST /orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		env.createRepo(t, w, r, r.PathValue("org"))
+	})
+
+	return env
+}
+
+func (env *testEnv) createRepo(t *testing.T, w http.ResponseWriter, r *http.Request, owner string) {
+	body := struct {
+		Name string `json:"name"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&body)
+	env.initBare(t, owner, body.Name)
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"name": %q, "full_name": "%s/%s"}`, body.Name, owner, body.Name)
+}
+
+// writeGitConfig writes the global git config, with an identity unless
+// identity is false.
+func (env *testEnv) writeGitConfig(t *testing.T, identity bool) {
+	config := "[init]\n\tdefaultBranch = master\n" +
+		"[commit]\n\tgpgsign = false\n" +
+		fmt.Sprintf("[url %q]\n\tinsteadOf = git@github.com:\n", env.remotes + "/")
+	if identity {
+		config += "[user]\n\tname = Test User\n\temail = test@example.com\n"
+	}
+	writeFile(t, os.Getenv("GIT_CONFIG_GLOBAL"), config)
+}
+
+func (env *testEnv) writeConfig(t *testing.T, lines ...string) {
+	writeFile(t, env.configPath, strings.Join(lines, "\n") + "\n")
+}
+
+// initBare creates the bare repository clones of owner/name come from and
+// returns its path.
+func (env *testEnv) initBare(t *testing.T, owner string, name string) string {
+	p := filepath.Join(env.remotes, owner, name + ".git")
+	git(t, "", "init", "-q", "--bare", p)
+	return p
+}
+
+// projPath is where a run creates the project dirName.
+func (env *testEnv) projPath(dirName string) string {
+	return filepath.Join(env.projDir, dirName)
+}
+
+// testConfig is a loaded config talking to api.
+func testConfig(api *mockApi) *appConfig {
+	config := &appConfig{
+		ghUsername: testUser,
+		ghApiKey: testToken,
+		host: "github",
+		mergeSettings: map[string]bool{},
+		retry: newRetryBudget(),
+		confirmDefault: true,
+		gitignoreHeader: true,
+	}
+	if api != nil {
+		config.apiBaseUrl = api.URL
+	}
+	return config
+}
+
+// testOptions parses args like the command line.
+func testOptions(args ...string) *appOptions {
+	opts := parseArgs(args)
+	return &opts
+}
+
+type runOutput struct {
+	stdout string
+	stderr string
+	code int
+}
+
+// runMain runs the program with args in dir, or the current dir when dir
+// is "", feeding it input on stdin.
+func runMain(t *testing.T, dir string, input string, args ...string) runOutput {
+	t.Helper()
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv + "=1")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	exitErr := &exec.ExitError{}
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("failed to run: %v", err)
+	}
+	return runOutput{stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()}
+}
+
+// mustRun is runMain for runs expected to succeed.
+func mustRun(t *testing.T, dir string, input string, args ...string) runOutput {
+	t.Helper()
+	out := runMain(t, dir, input, args...)
+	if out.code != 0 {
+		t.Fatalf("run %q exited with %d\nstdout:\n%s\nstderr:\n%s", args, out.code, out.stdout, out.stderr)
+	}
+	return out
+}
+
+// expectExit runs fn in a child process running just the current test and
+// returns what it printed and the code it exited with, for functions that
+// exit the program. Everything the test does before calling expectExit is
+// done again in the child.
+func expectExit(t *testing.T, fn func()) (string, int) {
+	t.Helper()
+
+	if os.Getenv(exitEnv) == t.Name() {
+		fn()
+		os.Exit(0)
+	}
+
+	pattern := []string{}
+	for _, part := range strings.Split(t.Name(), "/") {
+		pattern = append(pattern, "^" + regexp.QuoteMeta(part) + "$")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=" + strings.Join(pattern, "/"))
+	cmd.Env = append(os.Environ(), exitEnv + "=" + t.Name())
+	out, err := cmd.CombinedOutput()
+	exitErr := &exec.ExitError{}
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("failed to run child: %v", err)
+	}
+	return string(out), cmd.ProcessState.ExitCode()
+}
+
+// captureOutput sends everything printed through output to the returned
+// buffer for the duration of the test.
+func captureOutput(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	saved := output
+	output = &printer{stdout: buf, stderr: buf}
+	t.Cleanup(func() { output = saved })
+	return buf
+}
+
+// feedStdin makes prompts read input for the duration of the test.
+func feedStdin(t *testing.T, input string) {
+	saved := stdin
+	stdin = bufio.NewScanner(strings.NewReader(input))
+	t.Cleanup(func() { stdin = saved })
+}
+
+// git runs git in dir and returns its trimmed output.
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("/bin/git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, p string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, p string) string {
+	t.Helper()
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestMergeSettingsInCreatePayload(t *testing.T) {
+	env := newTestEnv(t, "allow_squash_merge = true", "allow_merge_commit = true")
+
+	mustRun(t, "", "", "--allow-merge-commit", "false", "--delete-branch-on-merge", "true", "merge-test")
+
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 {
+		t.Fatalf("got %d create requests, want 1", len(creates))
+	}
+	body := creates[0].json(t)
+
+	want := map[string]any{
+		"name": "merge-test",
+		"allow_squash_merge": true,
+		"allow_merge_commit": false,
+		"delete_branch_on_merge": true,
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+	for _, k := range []string{"allow_rebase_merge", "allow_auto_merge"} {
+		if _, ok := body[k]; ok {
+			t.Errorf("%s set although not configured", k)
+		}
+	}
+}
+
+func TestResumeUnpushedCommits(t *testing.T) {
+	env := newTestEnv(t)
+	bare := env.initBare(t, testUser, "resume")
+	projPath := env.projPath("resume")
+	git(t, env.projDir, "clone", "-q", "git@github.com:" + testUser + "/resume.git", "resume")
+	git(t, projPath, "checkout", "-q", "-b", "main")
+	git(t, projPath, "commit", "-q", "--allow-empty", "-m", "left behind")
+
+	if !isAheadOfOrigin(projPath) {
+		t.Fatal("clone with unpushed commit is not ahead of origin")
+	}
+
+	out := mustRun(t, "", "", "resume")
+
+	if !strings.Contains(out.stdout, "retrying push") {
+		t.Errorf("run did not resume push:\n%s", out.stdout)
+	}
+	if creates := env.api.received("POST", "/user/repos"); len(creates) > 0 {
+		t.Errorf("resumed run created repository again")
+	}
+	if got := git(t, bare, "log", "-1", "--format=%s", "main"); got != "left behind" {
+		t.Errorf("remote main is at %q, want the unpushed commit", got)
+	}
+	if isAheadOfOrigin(projPath) {
+		t.Errorf("clone still ahead of origin after resume")
+	}
+}
+
+func TestResumeOtherOrigin(t *testing.T) {
+	env := newTestEnv(t)
+	bare := env.initBare(t, testUser, "other")
+	projPath := env.projPath("resume")
+	git(t, env.projDir, "clone", "-q", "git@github.com:" + testUser + "/other.git", "resume")
+	git(t, projPath, "checkout", "-q", "-b", "main")
+	git(t, projPath, "commit", "-q", "--allow-empty", "-m", "not ours")
+
+	out := runMain(t, "", "", "resume")
+	if strings.Contains(out.stdout, "retrying push") {
+		t.Errorf("clone of another repository resumed:\n%s", out.stdout)
+	}
+	if got := git(t, bare, "branch", "--list", "main"); got != "" {
+		t.Errorf("commit pushed to the other repository")
+	}
+}
+
+func TestGitHooks(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--template", "go", "--git-hooks", "hooked")
+	projPath := env.projPath("hooked")
+
+	if got := git(t, projPath, "config", "core.hooksPath"); got != ".githooks" {
+		t.Errorf("core.hooksPath = %q, want .githooks", got)
+	}
+	if got := git(t, projPath, "ls-files", ".githooks"); got != ".githooks/pre-commit" {
+		t.Errorf("tracked hooks = %q, want .githooks/pre-commit", got)
+	}
+
+	info, err := os.Stat(filepath.Join(projPath, ".githooks", "pre-commit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Errorf("pre-commit hook is not executable: %v", info.Mode())
+	}
+	if hook := readFile(t, filepath.Join(projPath, ".githooks", "pre-commit")); !strings.Contains(hook, "gofmt") {
+		t.Errorf("go pre-commit hook does not run gofmt:\n%s", hook)
+	}
+}
+
+func TestApiFieldsInCreatePayload(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "",
+		"--api-field", "has_discussions=true",
+		"--api-field", "team_id=42",
+		"--api-field", "homepage=https://example.com",
+		"--api-field", `custom_properties={"tier": "gold"}`,
+		"fields")
+
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 {
+		t.Fatalf("got %d create requests, want 1", len(creates))
+	}
+	body := creates[0].json(t)
+
+	if body["has_discussions"] != true {
+		t.Errorf("has_discussions = %#v, want true", body["has_discussions"])
+	}
+	if body["team_id"] != float64(42) {
+		t.Errorf("team_id = %#v, want 42", body["team_id"])
+	}
+	if body["homepage"] != "https://example.com" {
+		t.Errorf("homepage = %#v, want the raw string", body["homepage"])
+	}
+	if props, _ := body["custom_properties"].(map[string]any); props["tier"] != "gold" {
+		t.Errorf("custom_properties = %#v, want an object", body["custom_properties"])
+	}
+}
+
+func TestInvalidApiField(t *testing.T) {
+	out, code := expectExit(t, func() { parseApiField("=true") })
+	if code != 1 || !strings.Contains(out, "expected KEY=VALUE") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestDirTransform(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--dir-transform", "strip-prefix:svc-", "--dir-transform", "lower", "svc-Billing")
+
+	if _, err := os.Stat(filepath.Join(env.projPath("billing"), ".git")); err != nil {
+		t.Fatalf("clone not in transformed dir: %v", err)
+	}
+	if _, err := os.Stat(env.projPath("svc-Billing")); err == nil {
+		t.Errorf("clone also created under the repository name")
+	}
+	if creates := env.api.received("POST", "/user/repos"); len(creates) != 1 || creates[0].json(t)["name"] != "svc-Billing" {
+		t.Errorf("repository not created under its untransformed name")
+	}
+}
+
+func TestDirTransformToEmptyName(t *testing.T) {
+	out, code := expectExit(t, func() { transformDirName("svc-", []string{"strip-prefix:svc-"}) })
+	if code != 1 || !strings.Contains(out, "empty directory name") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestGithubInitGitignoreTemplate(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--github-init", "--gitignore-template", "Go", "remote-init")
+
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 {
+		t.Fatalf("got %d create requests, want 1", len(creates))
+	}
+	body := creates[0].json(t)
+	if body["auto_init"] != true || body["gitignore_template"] != "Go" {
+		t.Errorf("auto_init = %v, gitignore_template = %v", body["auto_init"], body["gitignore_template"])
+	}
+	if fetches := env.api.received("GET", "/gitignore/templates/.*"); len(fetches) > 0 {
+		t.Errorf("fetched the gitignore template to write it locally")
+	}
+}
+
+func TestGitignoreTemplateWithoutGithubInit(t *testing.T) {
+	opts := testOptions("--gitignore-template", "Go", "local-init")
+	if _, ok := createRepoBody("local-init", testConfig(nil), opts)["gitignore_template"]; ok {
+		t.Errorf("gitignore_template sent without --github-init")
+	}
+}
+
+func TestPushesMainBranch(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"on-main"}, "main"},
+		{[]string{"--branch", "trunk", "on-trunk"}, "trunk"},
+	}
+
+	for _, tt := range tests {
+		env := newTestEnv(t)
+		mustRun(t, "", "", tt.args...)
+		name := tt.args[len(tt.args) - 1]
+
+		if got := git(t, env.projPath(name), "branch", "--show-current"); got != tt.want {
+			t.Errorf("%q: local branch %q, want %q", tt.args, got, tt.want)
+		}
+		bare := filepath.Join(env.remotes, testUser, name + ".git")
+		if got := git(t, bare, "for-each-ref", "--format=%(refname)", "refs/heads"); got != "refs/heads/" + tt.want {
+			t.Errorf("%q: remote branches %q, want only %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSignoff(t *testing.T) {
+	env := newTestEnv(t)
+	t.Setenv("GIT_AUTHOR_NAME", "Override Author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "override@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Override Author")
+	t.Setenv("GIT_COMMITTER_EMAIL", "override@example.com")
+	mustRun(t, "", "", "--signoff", "--co-author", "Pair <pair@example.com>", "dco")
+
+	msg := git(t, env.projPath("dco"), "log", "-1", "--format=%B")
+	for _, trailer := range []string{"Signed-off-by: Override Author <override@example.com>", "Co-authored-by: Pair <pair@example.com>"} {
+		if !strings.Contains(msg, trailer) {
+			t.Errorf("commit message lacks %q:\n%s", trailer, msg)
+		}
+	}
+}
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		max int
+		want string
+		warn bool
+	}{
+		{"", 5, "", false},
+		{"abcd", 5, "abcd", false},
+		{"abcde", 5, "abcde", false},
+		{"abcdef", 5, "abcd…", true},
+		{"ééééé", 5, "ééééé", false},
+		{"éééééé", 5, "éééé…", true},
+		{"ab", 1, "…", true},
+	}
+
+	for _, tt := range tests {
+		logged := captureOutput(t)
+		if got := truncateDescription(tt.description, tt.max); got != tt.want {
+			t.Errorf("truncateDescription(%q, %d) = %q, want %q", tt.description, tt.max, got, tt.want)
+		}
+		if warned := strings.Contains(logged.String(), "truncating"); warned != tt.warn {
+			t.Errorf("truncateDescription(%q, %d) warned: %v, want %v", tt.description, tt.max, warned, tt.warn)
+		}
+	}
+}
+
+func TestDescriptionMaxLen(t *testing.T) {
+	env := newTestEnv(t)
+	out := mustRun(t, "", "", "--description-max-len", "10", "--description", "A rather long description", "short")
+
+	if body := env.api.received("POST", "/user/repos")[0].json(t); body["description"] != "A rather …" {
+		t.Errorf("description = %q", body["description"])
+	}
+	if !strings.Contains(out.stderr, "Warning: description is longer than 10 characters") {
+		t.Errorf("no truncation warning:\n%s", out.stderr)
+	}
+}
+
+func TestMultilineDescription(t *testing.T) {
+	_, code := expectExit(t, func() { truncateDescription("one\ntwo", 100) })
+	if code != 1 {
+		t.Errorf("exit %d, want 1", code)
+	}
+}
+
+func TestAskDefault(t *testing.T) {
+	tests := []struct {
+		input string
+		defaultYes bool
+		want bool
+		prompt string
+	}{
+		{"\n", true, true, "Go on? (Y/n)\n"},
+		{"\n", false, false, "Go on? (y/N)\n"},
+		{"", false, false, "Go on? (y/N)\n"},
+		{"yes\n", false, true, "Go on? (y/N)\n"},
+		{"N\n", true, false, "Go on? (Y/n)\n"},
+	}
+
+	for _, tt := range tests {
+		logged := captureOutput(t)
+		feedStdin(t, tt.input)
+		if got := ask("Go on?", tt.defaultYes); got != tt.want {
+			t.Errorf("ask with %q, default yes %v = %v, want %v", tt.input, tt.defaultYes, got, tt.want)
+		}
+		if logged.String() != tt.prompt {
+			t.Errorf("prompt %q, want %q", logged, tt.prompt)
+		}
+	}
+}
+
+func TestConfirmDefaultNo(t *testing.T) {
+	env := newTestEnv(t, "confirm_default = no")
+	projPath := env.projPath("older")
+	git(t, env.projDir, "init", "-q", "older")
+
+	out := mustRun(t, "", "\n", "--reinit-existing", "older")
+
+	if !strings.Contains(out.stdout, "(y/N)") {
+		t.Errorf("prompt does not default to no:\n%s", out.stdout)
+	}
+	if _, err := os.Stat(filepath.Join(projPath, "README.md")); err == nil {
+		t.Errorf("empty input confirmed with confirm_default = no")
+	}
+}
+
+func TestProjectFileDescription(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".project"), "\ufeffname = old\n description = Migrated from elsewhere \n")
+	writeFile(t, filepath.Join(dir, "README.md"), "# Old\n\nFrom the readme.\n")
+
+	if got := readExistingDescription(dir); got != "Migrated from elsewhere" {
+		t.Errorf("description = %q, want the .project one", got)
+	}
+
+	os.Remove(filepath.Join(dir, ".project"))
+	if got := readExistingDescription(dir); got != "From the readme." {
+		t.Errorf("description without .project = %q", got)
+	}
+}
+
+func TestDescriptionFromProjectFile(t *testing.T) {
+	env := newTestEnv(t)
+	writeFile(t, filepath.Join(env.projPath("migrated"), ".project"), "description = Migrated from elsewhere\n")
+
+	out := mustRun(t, "", "", "--dry-run", "--print-plan", "json", "--description-from-git", "migrated")
+
+	plan := runPlan{}
+	if err := json.Unmarshal([]byte(out.stdout), &plan); err != nil {
+		t.Fatalf("plan is not json: %v\n%s", err, out.stdout)
+	}
+	if plan.Options["description"] != "Migrated from elsewhere" {
+		t.Errorf("description = %v", plan.Options["description"])
+	}
+}
+
+func TestNoNetworkFailsFast(t *testing.T) {
+	env := newTestEnv(t)
+
+	out := runMain(t, "", "", "--no-network", "--prune-default-labels", "offline")
+	if out.code != 1 {
+		t.Fatalf("exit %d, stderr %q", out.code, out.stderr)
+	}
+	if !strings.Contains(out.stderr, "--no-network is set but the run needs network for: repository creation, label pruning, clone, push") {
+		t.Errorf("stderr %q", out.stderr)
+	}
+	if len(env.api.requests) != 0 {
+		t.Errorf("api called despite --no-network: %v", env.api.requests)
+	}
+	if _, err := os.Stat(env.projPath("offline")); err == nil {
+		t.Errorf("project dir created before failing")
+	}
+
+	mustRun(t, "", "", "--no-network", "--dry-run", "offline")
+}
+
+func TestProjectsDirTrailingSlash(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeConfig(t,
+		"gh_username = " + testUser,
+		"gh_apikey = " + testToken,
+		"projects_dir = " + env.projDir + "//",
+		"api_url = " + env.api.URL,
+	)
+
+	out := mustRun(t, "", "", "--summary-only", "clean")
+	want := "created " + testUser + "/clean at " + env.projPath("clean") + " on main\n"
+	if !strings.HasSuffix(out.stdout, want) || strings.Contains(out.stdout, "projects//") {
+		t.Errorf("summary %q, want %q", out.stdout, want)
+	}
+}
+
+func TestConfigPathJoin(t *testing.T) {
+	unsetenv(t, configEnv)
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg/")
+
+	if got, want := getConfigPath(), filepath.Join("/tmp/xdg", "create-project", "config"); got != want {
+		t.Errorf("config path %q, want %q", got, want)
+	}
+}
+
+func TestMailmap(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--mailmap", "mapped")
+	projPath := env.projPath("mapped")
+
+	if got := readFile(t, filepath.Join(projPath, ".mailmap")); got != "Test User <test@example.com>\n" {
+		t.Errorf(".mailmap = %q", got)
+	}
+	if got := git(t, projPath, "ls-files", ".mailmap"); got != ".mailmap" {
+		t.Errorf(".mailmap not committed")
+	}
+}
+
+func TestMailmapWithoutIdentity(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeGitConfig(t, false)
+
+	out := runMain(t, "", "", "--mailmap", "mapped")
+	if out.code != 1 || !strings.Contains(out.stderr, "--mailmap") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+// fakeSsh puts an ssh on PATH that logs its arguments to the returned file
+// and answers with reply.
+func fakeSsh(t *testing.T, reply string) string {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "ssh.log")
+	writeFile(t, filepath.Join(dir, "ssh"), fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %s\necho %s >&2\nexit 1\n", shellQuote(log), shellQuote(reply)))
+	if err := os.Chmod(filepath.Join(dir, "ssh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir + string(os.PathListSeparator) + os.Getenv("PATH"))
+	return log
+}
+
+func TestVerifySsh(t *testing.T) {
+	env := newTestEnv(t)
+	log := fakeSsh(t, "Hi octocat! You've successfully authenticated, but GitHub does not provide shell access.")
+
+	mustRun(t, "", "", "--verify-ssh", "checked")
+
+	if got := readFile(t, log); got != "-T -o BatchMode=yes git@github.com\n" {
+		t.Errorf("ssh ran with %q", got)
+	}
+	if got := git(t, env.projPath("checked"), "log", "--format=%s"); got != "initial commit" {
+		t.Errorf("project not created after ssh check: %q", got)
+	}
+}
+
+func TestVerifySshFailure(t *testing.T) {
+	env := newTestEnv(t)
+	fakeSsh(t, "git@github.com: Permission denied (publickey).")
+
+	out := runMain(t, "", "", "--verify-ssh", "checked")
+	if out.code != 1 || !strings.Contains(out.stderr, "SSH access to github.com failed, check your ssh keys:\ngit@github.com: Permission denied (publickey).") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+	if posts := env.api.received("POST", ".*"); len(posts) > 0 {
+		t.Errorf("repository created despite failed ssh check")
+	}
+}
+
+func TestNameFrom(t *testing.T) {
+	env := newTestEnv(t)
+	nameFile := filepath.Join(t.TempDir(), "name")
+	writeFile(t, nameFile, "\ufeff  from-file\n")
+
+	mustRun(t, "", "", "--name-from", nameFile)
+	if got := git(t, env.projPath("from-file"), "log", "--format=%s"); got != "initial commit" {
+		t.Errorf("project not created from name file: %q", got)
+	}
+
+	writeFile(t, nameFile, "..\n")
+	out := runMain(t, "", "", "--name-from", nameFile)
+	if out.code != 1 || !strings.Contains(out.stderr, `Invalid project name: ".."`) {
+		t.Errorf("name file with ..: exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestInvalidProjName(t *testing.T) {
+	for i, name := range []string{".", "..", "...", "a--b", "-a", "a/b", "émoji"} {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			out, code := expectExit(t, func() { validateProjName(name) })
+			if code != 1 || !strings.Contains(out, "Invalid project name") {
+				t.Errorf("%q: exit %d, output %q", name, code, out)
+			}
+		})
+	}
+	for _, name := range []string{"a.b", ".dotfiles", "a_b-c", "v1.2"} {
+		if got := validateProjName(name); got != name {
+			t.Errorf("validateProjName(%q) = %q", name, got)
+		}
+	}
+}
+
+func TestSplitFullNameValidates(t *testing.T) {
+	tests := []struct {
+		in string
+		want string
+	}{
+		{"octocat", "Invalid repository, expected OWNER/NAME"},
+		{"-bad-/repo", "Invalid owner"},
+		{"octocat/..", "Invalid project name"},
+		{"octocat/a b", "Invalid project name"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			out, code := expectExit(t, func() { splitFullName(tt.in) })
+			if code != 1 || !strings.Contains(out, tt.want) {
+				t.Errorf("%q: exit %d, output %q", tt.in, code, out)
+			}
+		})
+	}
+
+	newTestEnv(t)
+	for _, flag := range []string{"--fork", "--contribute"} {
+		out := runMain(t, "", "", flag, "octocat/..")
+		if out.code != 1 || !strings.Contains(out.stderr, `Invalid project name: ".."`) {
+			t.Errorf("%s: exit %d, stderr %q", flag, out.code, out.stderr)
+		}
+	}
+}
+
+func TestHere(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeConfig(t,
+		"gh_username = " + testUser,
+		"gh_apikey = " + testToken,
+		"api_url = " + env.api.URL,
+	)
+	cwd := t.TempDir()
+
+	mustRun(t, cwd, "", "--here", "local")
+	if got := git(t, filepath.Join(cwd, "local"), "log", "--format=%s"); got != "initial commit" {
+		t.Errorf("project not cloned into the current dir: %q", got)
+	}
+
+	out := runMain(t, cwd, "", "elsewhere")
+	if out.code != 1 || !strings.Contains(out.stderr, "Config is missing projects_dir, set it or use --here") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestAuthors(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--authors", "credited")
+	projPath := env.projPath("credited")
+
+	if got := readFile(t, filepath.Join(projPath, "AUTHORS")); got != "# Authors of Credited\n\nTest User <test@example.com>\n" {
+		t.Errorf("AUTHORS = %q", got)
+	}
+	if got := git(t, projPath, "ls-files", "AUTHORS"); got != "AUTHORS" {
+		t.Errorf("AUTHORS not committed")
+	}
+}
+
+func TestConfigOverrideWithoutConfigDir(t *testing.T) {
+	env := newTestEnv(t)
+	unsetenv(t, "XDG_CONFIG_HOME")
+	unsetenv(t, "HOME")
+
+	out := runMain(t, "", "", "--dry-run", "nohome")
+	if out.code != 0 {
+		t.Errorf("CREATE_PROJECT_CONFIG not used without a config dir: exit %d, stderr %q", out.code, out.stderr)
+	}
+
+	unsetenv(t, configEnv)
+	out = runMain(t, "", "", "--config", env.configPath, "--dry-run", "nohome")
+	if out.code != 0 {
+		t.Errorf("--config not used without a config dir: exit %d, stderr %q", out.code, out.stderr)
+	}
+
+	out = runMain(t, "", "", "--dry-run", "nohome")
+	if out.code != 1 || !strings.Contains(out.stderr, "Set " + configEnv + " or use --config to point to the config file") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestAddPatterns(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--template", "go", "--owner", testUser, "--add", "*.md", "--add", "go.*", "picked")
+	projPath := env.projPath("picked")
+
+	if got := git(t, projPath, "ls-files"); got != "README.md\ngo.mod" {
+		t.Errorf("committed %q, want just README.md and go.mod", got)
+	}
+	if got := git(t, projPath, "status", "--porcelain"); !strings.Contains(got, "?? main.go") || !strings.Contains(got, "?? .gitignore") {
+		t.Errorf("files outside --add not left untracked:\n%s", got)
+	}
+}
+
+func TestConfigWithBom(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeConfig(t,
+		"\ufeffgh_username = " + testUser,
+		"gh_apikey = " + testToken,
+		"projects_dir = " + env.projDir,
+		"api_url = " + env.api.URL,
+	)
+
+	config := appConfig{}
+	config.load()
+	if config.ghUsername != testUser {
+		t.Errorf("first key after a BOM: gh_username = %q", config.ghUsername)
+	}
+
+	template := initTemplateRepo(t, map[string]string{
+		templateManifestName: "\ufeff" + `{"variables": [{"name": "port", "default": "8080"}]}`,
+	})
+	if manifest := loadTemplateManifest(template); len(manifest.Variables) != 1 {
+		t.Errorf("manifest with a BOM: %+v", manifest)
+	}
+}
+
+func TestCoAuthors(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--co-author", "Ada Lovelace <ada@example.com>", "--co-author", " Alan Turing <alan@example.com> ", "paired")
+
+	want := "initial commit\n\nCo-authored-by: Ada Lovelace <ada@example.com>\nCo-authored-by: Alan Turing <alan@example.com>"
+	if got := git(t, env.projPath("paired"), "log", "-1", "--format=%B"); got != want {
+		t.Errorf("commit message %q, want %q", got, want)
+	}
+	trailers := git(t, env.projPath("paired"), "log", "-1", "--format=%(trailers:key=Co-authored-by,valueonly)")
+	if trailers != "Ada Lovelace <ada@example.com>\nAlan Turing <alan@example.com>" {
+		t.Errorf("git does not see the trailers: %q", trailers)
+	}
+}
+
+func TestInvalidCoAuthor(t *testing.T) {
+	newTestEnv(t)
+	out := runMain(t, "", "", "--co-author", "Ada", "paired")
+	if out.code != 1 || !strings.Contains(out.stderr, `Invalid co-author, expected "NAME <EMAIL>": Ada`) {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestGenConfigScopeHint(t *testing.T) {
+	env := newTestEnv(t)
+	configPath := filepath.Join(env.dir, "fresh", "config")
+	t.Setenv(configEnv, configPath)
+
+	out := mustRun(t, "", "", "--gen-config")
+
+	config := readFile(t, configPath)
+	wantComment := "# The github api key needs the repo scope (classic token) or\n" +
+		"# administration: write and contents: write (fine-grained token)\n"
+	if !strings.HasPrefix(config, wantComment) {
+		t.Errorf("config does not start with the scope hint:\n%s", config)
+	}
+	if !strings.Contains(config, "gh_apikey    = github api key\n") {
+		t.Errorf("config has no api key field:\n%s", config)
+	}
+	if !strings.Contains(out.stdout, tokenScopeHint) {
+		t.Errorf("scope hint not printed:\n%s", out.stdout)
+	}
+}
+
+func TestDefaultDirs(t *testing.T) {
+	env := newTestEnv(t, "default_dirs = src, docs/api, tests")
+
+	mustRun(t, "", "", "laid-out")
+	got := git(t, env.projPath("laid-out"), "ls-files", "*.gitkeep")
+	if got != "docs/api/.gitkeep\nsrc/.gitkeep\ntests/.gitkeep" {
+		t.Errorf("committed %q", got)
+	}
+
+	mustRun(t, "", "", "--dirs", "lib", "overridden")
+	if got := git(t, env.projPath("overridden"), "ls-files", "*.gitkeep"); got != "lib/.gitkeep" {
+		t.Errorf("--dirs did not override default_dirs: %q", got)
+	}
+
+	mustRun(t, "", "", "--dirs", "", "bare")
+	if got := git(t, env.projPath("bare"), "ls-files", "*.gitkeep"); got != "" {
+		t.Errorf("--dirs \"\" did not drop default_dirs: %q", got)
+	}
+}
+
+func TestSummaryOnly(t *testing.T) {
+	env := newTestEnv(t)
+
+	out := mustRun(t, "", "", "--summary-only", "quiet")
+	prompt := "Create project " + env.projPath("quiet") + " (Y/n)\n"
+	summary := "created " + testUser + "/quiet at " + env.projPath("quiet") + " on main\n"
+	if out.stdout != prompt + summary {
+		t.Errorf("stdout %q, want just the prompt and %q", out.stdout, summary)
+	}
+	if out.stderr != "" {
+		t.Errorf("stderr %q", out.stderr)
+	}
+
+	out = runMain(t, "", "", "--summary-only", "bad..name?")
+	if out.code != 1 || out.stdout != "" || out.stderr != "Invalid project name: \"bad..name?\"\n" {
+		t.Errorf("failure: exit %d, stdout %q, stderr %q", out.code, out.stdout, out.stderr)
+	}
+}
+
+func TestEmptyPush(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("DELETE /repos/" + testUser + "/{name}", http.StatusNoContent, "")
+
+	out := mustRun(t, "", "", "--no-default-files", "skipped")
+	if !strings.Contains(out.stderr, "Warning: nothing to commit, skipping push, " + testUser + "/skipped is left empty") {
+		t.Errorf("no warning about the empty push:\n%s", out.stderr)
+	}
+	if got := git(t, env.remotes, "--git-dir", testUser + "/skipped.git", "for-each-ref"); got != "" {
+		t.Errorf("something was pushed: %q", got)
+	}
+
+	out = runMain(t, "", "", "--no-default-files", "--delete-on-empty-push", "deleted")
+	if out.code != 1 || !strings.Contains(out.stderr, "Nothing to commit, deleted empty repository " + testUser + "/deleted") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+	if got := len(env.api.received("DELETE", "/repos/" + testUser + "/deleted")); got != 1 {
+		t.Errorf("empty repository deleted %d times, want once", got)
+	}
+
+	out = runMain(t, "", "", "--no-default-files", "--delete-on-empty-push", "--local-first", "never")
+	if out.code != 1 || !strings.Contains(out.stderr, "Nothing to commit, not creating repository " + testUser + "/never") {
+		t.Errorf("local first: exit %d, stderr %q", out.code, out.stderr)
+	}
+	out = runMain(t, "", "", "--no-default-files", "--local-first", "nothing")
+	if out.code != 1 || !strings.Contains(out.stderr, "Nothing to commit, not creating repository " + testUser + "/nothing") {
+		t.Errorf("local first without --delete-on-empty-push: exit %d, stderr %q", out.code, out.stderr)
+	}
+	if got := len(env.api.received("POST", "/user/repos")); got != 2 {
+		t.Errorf("%d repositories created, want 2 without the --local-first ones", got)
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--changelog", "logged-tool")
+	projPath := env.projPath("logged-tool")
+
+	changelog := readFile(t, filepath.Join(projPath, "CHANGELOG.md"))
+	if !strings.HasPrefix(changelog, "# Changelog of Logged Tool\n") {
+		t.Errorf("CHANGELOG.md does not start with the title:\n%s", changelog)
+	}
+	if !strings.HasSuffix(changelog, "\n## [Unreleased]\n") || !strings.Contains(changelog, "[Keep a Changelog](https://keepachangelog.com/en/1.1.0/)") {
+		t.Errorf("CHANGELOG.md has no Unreleased section:\n%s", changelog)
+	}
+	if got := git(t, projPath, "ls-files", "CHANGELOG.md"); got != "CHANGELOG.md" {
+		t.Errorf("CHANGELOG.md not committed")
+	}
+}
+
+func TestOwnerNamePositional(t *testing.T) {
+	opts := testOptions("acme/my-app")
+	if opts.owner != "acme" || opts.projName != "my-app" {
+		t.Errorf("owner %q, name %q", opts.owner, opts.projName)
+	}
+
+	opts = testOptions("--owner", "acme", "acme/one", "two")
+	if opts.owner != "acme" || !slices.Equal(opts.projNames, []string{"one", "two"}) {
+		t.Errorf("owner %q, names %q", opts.owner, opts.projNames)
+	}
+
+	tests := [][]string{
+		{"-acme/my-app"},
+		{"acme/my app"},
+		{"acme/.."},
+		{"acme/one", "other/two"},
+		{"--owner", "other", "acme/my-app"},
+	}
+	for i, args := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			out, code := expectExit(t, func() { parseArgs(args) })
+			if code != 1 || !strings.Contains(out, "Invalid") && !strings.Contains(out, "Conflicting owners") {
+				t.Errorf("%q: exit %d, output %q", args, code, out)
+			}
+		})
+	}
+}
+
+func TestOwnerNamePositionalRun(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--owner-type", "org", "acme/my-app")
+
+	if got := len(env.api.received("POST", "/orgs/acme/repos")); got != 1 {
+		t.Errorf("repository not created under acme")
+	}
+	if url := git(t, env.projPath("my-app"), "config", "remote.origin.url"); url != "git@github.com:acme/my-app.git" {
+		t.Errorf("origin = %q", url)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		visual string
+		editor string
+		config string
+		want []string
+	}{
+		{"code --wait", "vi", "nano", []string{"code", "--wait", "."}},
+		{"", "vi", "nano", []string{"vi", "."}},
+		{"", "", "nano -w", []string{"nano", "-w", "."}},
+	}
+
+	projPath := t.TempDir()
+	for _, tt := range tests {
+		t.Setenv("VISUAL", tt.visual)
+		t.Setenv("EDITOR", tt.editor)
+
+		cmd := editorCommand(projPath, &appConfig{editor: tt.config})
+		if cmd == nil || !slices.Equal(cmd.Args, tt.want) || cmd.Dir != projPath {
+			t.Errorf("%+v: command %v", tt, cmd)
+		}
+	}
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if cmd := editorCommand(projPath, &appConfig{}); cmd != nil {
+		t.Errorf("command without any editor: %v", cmd.Args)
+	}
+}
+
+func TestEditRun(t *testing.T) {
+	env := newTestEnv(t)
+	log := filepath.Join(t.TempDir(), "editor.log")
+	editor := filepath.Join(t.TempDir(), "editor")
+	writeFile(t, editor, "#!/bin/sh\necho \"$PWD $@\" > " + shellQuote(log) + "\n")
+	if err := os.Chmod(editor, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", editor)
+
+	mustRun(t, "", "", "--edit", "edited")
+	if got := readFile(t, log); got != env.projPath("edited") + " .\n" {
+		t.Errorf("editor ran as %q", got)
+	}
+}
+
+func TestNoDefaultFiles(t *testing.T) {
+	env := newTestEnv(t, "default_template = go", "default_dirs = src, docs")
+	handleLicenses(env.api)
+
+	mustRun(t, "", "", "--no-default-files", "--license", "MIT", "--changelog", "--owner", testUser, "minimal")
+
+	if got := git(t, env.projPath("minimal"), "ls-files"); got != "CHANGELOG.md\nLICENSE" {
+		t.Errorf("committed %q, want only the explicit additions", got)
+	}
+
+	mustRun(t, "", "", "--no-default-files", "--template", "go", "--owner", testUser, "explicit")
+	if got := git(t, env.projPath("explicit"), "ls-files"); got != "go.mod\nmain.go" {
+		t.Errorf("explicit template: committed %q", got)
+	}
+}
+
+func TestReadmeDescription(t *testing.T) {
+	tests := []struct {
+		readme string
+		want string
+	}{
+		{"# Tool\n\nDoes one thing\nand does it well.\n\nMore details.\n", "Does one thing and does it well."},
+		{"Tool\n====\n\n[![CI](https://ci/badge.svg)](https://ci)\n<p align=\"center\"></p>\n\nFirst paragraph.\n", "First paragraph."},
+		{"# Tool\n\n```sh\nmake install\n```\n\nAfter the code.\n", "After the code."},
+		{"\ufeff# Only a heading\n", ""},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "README.md"), tt.readme)
+		if got := readReadmeDescription(dir); got != tt.want {
+			t.Errorf("%q: description %q, want %q", tt.readme, got, tt.want)
+		}
+	}
+
+	if got := readReadmeDescription(t.TempDir()); got != "" {
+		t.Errorf("description without README.md = %q", got)
+	}
+}
+
+func TestDescriptionFromReadme(t *testing.T) {
+	env := newTestEnv(t)
+	writeFile(t, filepath.Join(env.projPath("existing"), "README.md"), "# Existing\n\nAn existing tool.\n")
+
+	out := mustRun(t, "", "", "--dry-run", "--print-plan", "json", "--description-from-git", "existing")
+
+	plan := runPlan{}
+	if err := json.Unmarshal([]byte(out.stdout), &plan); err != nil {
+		t.Fatalf("plan is not json: %v\n%s", err, out.stdout)
+	}
+	if plan.Options["description"] != "An existing tool." {
+		t.Errorf("description = %v", plan.Options["description"])
+	}
+
+	out = mustRun(t, "", "", "--dry-run", "--print-plan", "json", "--description-from-git", "--description", "Given", "existing")
+	plan = runPlan{}
+	json.Unmarshal([]byte(out.stdout), &plan)
+	if plan.Options["description"] != "Given" {
+		t.Errorf("--description overridden by README.md: %v", plan.Options["description"])
+	}
+}
+
+func TestDirPermissionsCheck(t *testing.T) {
+	logged := captureOutput(t)
+	root := t.TempDir()
+	for _, d := range []string{"open", "sticky/projects", "closed"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Chmod, the umask would hold back the world-writable bit.
+	for d, mode := range map[string]os.FileMode{"open": 0777, "sticky": 0777 | os.ModeSticky} {
+		if err := os.Chmod(filepath.Join(root, d), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkDirPermissions(filepath.Join(root, "open"))
+	want := "Warning: " + filepath.Join(root, "open") + " is world-writable"
+	if !strings.Contains(logged.String(), want) {
+		t.Errorf("no warning for world-writable projects dir:\n%s", logged)
+	}
+
+	logged.Reset()
+	checkDirPermissions(filepath.Join(root, "sticky/projects"))
+	checkDirPermissions(filepath.Join(root, "closed"))
+	if logged.Len() > 0 {
+		t.Errorf("warned about a sticky parent or closed dir:\n%s", logged)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in string
+		want string
+	}{
+		{"My Cool App", "my-cool-app"},
+		{"  Tom's   C++ tools!  ", "toms-c-tools"},
+		{"Café Crème", "cafe-creme"},
+		{"Straße 2000", "strasse-2000"},
+		{"Łódź Ørsted", "lodz-orsted"},
+	}
+
+	for _, tt := range tests {
+		if got := slugify(tt.in); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlugifyRejects(t *testing.T) {
+	tests := []struct {
+		in string
+		want string
+	}{
+		{"Привет мир", "Title has letters that cannot be spelled in ASCII"},
+		{"Cool 東京 App", "Title has letters that cannot be spelled in ASCII"},
+		{"!!!", "Title has no letters or digits"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			logged, code := expectExit(t, func() { slugify(tt.in) })
+			if code != 1 || !strings.HasPrefix(logged, tt.want) {
+				t.Errorf("slugify(%q): exit %d, printed %q", tt.in, code, logged)
+			}
+		})
+	}
+}
+
+func TestTitle(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--title", "My Café App")
+
+	readme := readFile(t, filepath.Join(env.projPath("my-cafe-app"), "README.md"))
+	if strings.SplitN(readme, "\n", 2)[0] != "# My Café App" {
+		t.Errorf("README.md does not start with the title verbatim:\n%s", readme)
+	}
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 || creates[0].json(t)["name"] != "my-cafe-app" {
+		t.Errorf("create requests %v, want one for my-cafe-app", creates)
+	}
+}
+
+func TestVersionFile(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--version-file", "0.1.0", "versioned")
+
+	if got := readFile(t, filepath.Join(env.projPath("versioned"), "VERSION")); got != "0.1.0\n" {
+		t.Errorf("VERSION %q, want \"0.1.0\\n\"", got)
+	}
+	if files := git(t, env.projPath("versioned"), "ls-files"); !strings.Contains(files, "VERSION") {
+		t.Errorf("VERSION not committed:\n%s", files)
+	}
+}
+
+func TestInvalidVersionFile(t *testing.T) {
+	for i, v := range []string{"one", "1..0", "1.0 beta"} {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			logged, code := expectExit(t, func() { testOptions("--version-file", v, "demo") })
+			if code != 1 || !strings.HasPrefix(logged, "Invalid version, expected e.g. 0.1.0: " + v + "\n") {
+				t.Errorf("%q: exit %d, printed %q", v, code, logged)
+			}
+		})
+	}
+}
+
+func TestTimeoutMidClone(t *testing.T) {
+	env := newTestEnv(t)
+	// Without the insteadOf of the test env the clone goes over ssh, which
+	// hangs until it is killed.
+	writeFile(t, os.Getenv("GIT_CONFIG_GLOBAL"), "[user]\n\tname = Test User\n\temail = test@example.com\n")
+	ssh := filepath.Join(env.dir, "ssh")
+	writeFile(t, ssh, "#!/bin/sh\nexec sleep 30 2>/dev/null\n")
+	if err := os.Chmod(ssh, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_SSH_COMMAND", ssh)
+
+	start := time.Now()
+	out := runMain(t, "", "", "--timeout", "1s", "hanging")
+	if out.code != 1 || !strings.Contains(out.stderr, "Failed to clone repository: run timed out after 1s\n") {
+		t.Errorf("exit %d, stderr:\n%s", out.code, out.stderr)
+	}
+	if elapsed := time.Since(start); elapsed > 10 * time.Second {
+		t.Errorf("run took %v, the clone was not aborted", elapsed)
+	}
+	if len(env.api.received("POST", "/user/repos")) != 1 {
+		t.Errorf("aborted before the clone")
+	}
+}
+
+func TestIsAheadOfOriginWithoutOrigin(t *testing.T) {
+	newTestEnv(t)
+	projPath := t.TempDir()
+	git(t, projPath, "init", "-q")
+	git(t, projPath, "commit", "-q", "--allow-empty", "-m", "local only")
+
+	if isAheadOfOrigin(projPath) {
+		t.Errorf("repository without origin is ahead of it")
+	}
+	if !isUnpublished(projPath) {
+		t.Errorf("repository with commits and no origin is not unpublished")
+	}
+}
+
+func TestLocalFirstScaffoldFailure(t *testing.T) {
+	env := newTestEnv(t)
+	// A file where the FUNDING.yml dir goes fails the scaffold after the
+	// template was copied.
+	template := initTemplateRepo(t, map[string]string{".github": "not a dir\n"})
+
+	out := runMain(t, "", "", "--local-first", "--template-git", template, "--funding", "github:octocat", "broken")
+	if out.code != 1 || !strings.Contains(out.stderr, "Failed to create .github dir") {
+		t.Fatalf("exit %d, stderr:\n%s", out.code, out.stderr)
+	}
+	if creates := env.api.received("POST", "/user/repos"); len(creates) > 0 {
+		t.Errorf("repository created although scaffolding failed")
+	}
+	if _, err := os.Stat(filepath.Join(env.remotes, testUser, "broken.git")); !os.IsNotExist(err) {
+		t.Errorf("remote exists although scaffolding failed")
+	}
+}
+
+func TestLocalFirstResume(t *testing.T) {
+	env := newTestEnv(t)
+	denied := true
+	env.api.handleFunc("POST /orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		if denied {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"message": "Resource not accessible"}`)
+			return
+		}
+		env.createRepo(t, w, r, "acme")
+	})
+	args := []string{"--owner", "acme", "--owner-type", "org", "--local-first", "resumed"}
+
+	out := runMain(t, "", "", args...)
+	if out.code != 1 || !strings.Contains(out.stderr, "Failed to create repository") {
+		t.Fatalf("exit %d, stderr:\n%s", out.code, out.stderr)
+	}
+	projPath := env.projPath("resumed")
+	if !isUnpublished(projPath) {
+		t.Fatalf("failed create left no local commits behind")
+	}
+
+	denied = false
+	out = mustRun(t, "", "", args...)
+	if !strings.Contains(out.stdout, "without origin, resuming") {
+		t.Errorf("run did not resume:\n%s", out.stdout)
+	}
+	if got := git(t, projPath, "config", "remote.origin.url"); got != "git@github.com:acme/resumed.git" {
+		t.Errorf("origin %q", got)
+	}
+	bare := filepath.Join(env.remotes, "acme", "resumed.git")
+	if got, want := git(t, bare, "rev-parse", "main"), git(t, projPath, "rev-parse", "HEAD"); got != want {
+		t.Errorf("remote main at %s, want the local commit %s", got, want)
+	}
+	if isAheadOfOrigin(projPath) || isUnpublished(projPath) {
+		t.Errorf("project still not published after resume")
+	}
+}
+
+func TestPrintCloneCommand(t *testing.T) {
+	env := newTestEnv(t)
+	cwd := filepath.Join(env.dir, "my projects")
+	if err := os.Mkdir(cwd, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := mustRun(t, cwd, "", "--print-clone-command", "--here", "--owner", "acme", "--owner-type", "org", "--dir-transform", "strip-prefix:go-", "go-cloned")
+	want := "git clone git@github.com:acme/go-cloned.git '" + filepath.Join(cwd, "cloned") + "'\n"
+	if out.stdout != want {
+		t.Errorf("stdout %q, want just %q", out.stdout, want)
+	}
+	if len(env.api.requests) > 0 {
+		t.Errorf("api requests made: %v", env.api.requests)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, "cloned")); !os.IsNotExist(err) {
+		t.Errorf("project dir created")
+	}
+}
+
+func TestFixIdentityEmptyEmail(t *testing.T) {
+	newTestEnv(t)
+	logged := captureOutput(t)
+	feedStdin(t, "y\n")
+	projPath := t.TempDir()
+	git(t, projPath, "init", "-q")
+	writeFile(t, filepath.Join(projPath, "README.md"), "# demo\n")
+	git(t, projPath, "add", ".")
+	git(t, projPath, "-c", "user.email=", "commit", "-q", "-m", "initial commit")
+
+	fixIdentity(projPath)
+
+	if !strings.Contains(logged.String(), "Initial commit was authored as <>, amend it with Test User <test@example.com>") {
+		t.Errorf("no amend prompt:\n%s", logged)
+	}
+	if got := git(t, projPath, "log", "-1", "--format=%an <%ae>|%s"); got != "Test User <test@example.com>|initial commit" {
+		t.Errorf("commit %q, want it amended with the configured identity", got)
+	}
+}
+
+func TestFixIdentityWithoutIdentity(t *testing.T) {
+	env := newTestEnv(t)
+	logged := captureOutput(t)
+	projPath := t.TempDir()
+	git(t, projPath, "init", "-q")
+	writeFile(t, filepath.Join(projPath, "README.md"), "# demo\n")
+	git(t, projPath, "add", ".")
+	git(t, projPath, "-c", "user.name=Someone", "-c", "user.email=", "commit", "-q", "-m", "initial commit")
+	env.writeGitConfig(t, false)
+	feedStdin(t, "y\nFixed Name\nfixed@example.com\n")
+
+	fixIdentity(projPath)
+
+	if !strings.Contains(logged.String(), "git user.name and user.email are not set") {
+		t.Errorf("no identity prompt:\n%s", logged)
+	}
+	if got := git(t, projPath, "log", "-1", "--format=%an <%ae>"); got != "Fixed Name <fixed@example.com>" {
+		t.Errorf("commit authored by %q", got)
+	}
+	if got := git(t, projPath, "config", "--local", "user.email"); got != "fixed@example.com" {
+		t.Errorf("project user.email %q", got)
+	}
+}
+
+func TestGitignoreHeader(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("GET /gitignore/templates/Go", http.StatusOK, `{"name": "Go", "source": "*.exe\n"}`)
+	header := "# Generated by create-project from the Go gitignore template\n\n"
+
+	mustRun(t, "", "", "--gitignore-template", "Go", "headed")
+	if got := readFile(t, filepath.Join(env.projPath("headed"), ".gitignore")); got != header + "*.exe\n" {
+		t.Errorf(".gitignore %q, want the header before the template", got)
+	}
+
+	mustRun(t, "", "", "--gitignore-template", "Go", "--no-gitignore-header", "flagged")
+	if got := readFile(t, filepath.Join(env.projPath("flagged"), ".gitignore")); got != "*.exe\n" {
+		t.Errorf(".gitignore %q with --no-gitignore-header", got)
+	}
+
+	env.writeConfig(t,
+		"gh_username = " + testUser,
+		"gh_apikey = " + testToken,
+		"projects_dir = " + env.projDir,
+		"api_url = " + env.api.URL,
+		"gitignore_header = false",
+	)
+	mustRun(t, "", "", "--gitignore-template", "Go", "configured")
+	if got := readFile(t, filepath.Join(env.projPath("configured"), ".gitignore")); got != "*.exe\n" {
+		t.Errorf(".gitignore %q with gitignore_header = false", got)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// templateManifestName is the file a template repository may ship to ask
+// for its own variables. It is not copied into the project.
+const templateManifestName = "template.json"
+
+type templatePrompt struct {
+	Name string `json:"name"`
+	Prompt string `json:"prompt"`
+	Default string `json:"default"`
+	Pattern string `json:"pattern"`
+}
+
+type templateManifest struct {
+	Extends string `json:"extends"`
+	Description string `json:"description"`
+	Variables []templatePrompt `json:"variables"`
+}
+
+func loadTemplateManifest(dir string) templateManifest {
+	manifest, err := readTemplateManifest(dir)
+	if err != nil {
+		output.error("%v\n", err)
+		os.Exit(1)
+	}
+	return manifest
+}
+
+// readTemplateManifest is loadTemplateManifest for callers that report
+// problems instead of exiting on them.
+func readTemplateManifest(dir string) (templateManifest, error) {
+	manifest := templateManifest{}
+
+	data, err := os.ReadFile(filepath.Join(dir, templateManifestName))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, fmt.Errorf("Failed to read template manifest: %v", err)
+	}
+
+	err = json.Unmarshal([]byte(stripBom(string(data))), &manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("Failed to parse template manifest: %v", err)
+	}
+
+	for _, v := range manifest.Variables {
+		if v.Name == "" {
+			return manifest, fmt.Errorf("Template manifest has variable without name")
+		}
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return manifest, fmt.Errorf("Invalid pattern for template variable %s: %v", v.Name, err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// collectTemplateVars prompts for every manifest variable missing from
+// given on stdin. Empty input takes the default, input not matching the
+// pattern is asked again. The result holds given as well.
+func collectTemplateVars(manifest templateManifest, given map[string]string) map[string]string {
+	vars := map[string]string{}
+	for k, v := range given {
+		vars[k] = v
+	}
+
+	for _, v := range manifest.Variables {
+		if value, ok := given[v.Name]; ok {
+			if !regexp.MustCompile(v.Pattern).MatchString(value) {
+				output.error("Value for %s must match %s\n", v.Name, v.Pattern)
+				os.Exit(1)
+			}
+			continue
+		}
+
+		prompt := v.Prompt
+		if prompt == "" {
+			prompt = v.Name
+		}
+		pattern := regexp.MustCompile(v.Pattern)
+
+		for {
+			output.result("%s [%s]: ", prompt, v.Default)
+
+			input, ok := readLine()
+			if input == "" {
+				input = v.Default
+			}
+
+			if pattern.MatchString(input) {
+				vars[v.Name] = input
+				break
+			}
+
+			output.error("Value for %s must match %s\n", v.Name, v.Pattern)
+			if !ok {
+				os.Exit(1)
+			}
+		}
+	}
+
+	return vars
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectTemplateVars(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, templateManifestName), `{
+		"variables": [
+			{"name": "port", "prompt": "Port", "default": "8080", "pattern": "^[0-9]+$"},
+			{"name": "db", "default": "sqlite"},
+			{"name": "author"}
+		]
+	}`)
+
+	manifest := loadTemplateManifest(dir)
+	if len(manifest.Variables) != 3 {
+		t.Fatalf("loaded %d variables", len(manifest.Variables))
+	}
+
+	logged := captureOutput(t)
+	feedStdin(t, "eighty\n9000\n\n")
+	vars := collectTemplateVars(manifest, map[string]string{"author": "Mona", "name": "app"})
+
+	want := map[string]string{"port": "9000", "db": "sqlite", "author": "Mona", "name": "app"}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("%s = %q, want %q", k, vars[k], v)
+		}
+	}
+	if len(vars) != len(want) {
+		t.Errorf("vars = %v", vars)
+	}
+	if strings.Count(logged.String(), "Port [8080]: ") != 2 || strings.Contains(logged.String(), "author [") {
+		t.Errorf("prompts:\n%s", logged)
+	}
+}
+
+func TestInvalidTemplateManifest(t *testing.T) {
+	tests := []struct {
+		manifest string
+		want string
+	}{
+		{`{"variables": [{"prompt": "Port"}]}`, "variable without name"},
+		{`{"variables": [{"name": "port", "pattern": "("}]}`, "Invalid pattern for template variable port"},
+		{`{"variables": `, "Failed to parse template manifest"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, templateManifestName), tt.manifest)
+		if _, err := readTemplateManifest(dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want %q", tt.manifest, err, tt.want)
+		}
+	}
+}
+
+func TestTemplateManifestPrompts(t *testing.T) {
+	env := newTestEnv(t)
+	template := initTemplateRepo(t, map[string]string{
+		templateManifestName: `{"variables": [{"name": "port", "default": "8080"}]}`,
+		"config.env": "PORT={{port}}\n",
+	})
+
+	mustRun(t, "", "y\n9000\n", "--template-git", template, "served")
+	projPath := env.projPath("served")
+
+	if got := readFile(t, filepath.Join(projPath, "config.env")); got != "PORT=9000\n" {
+		t.Errorf("config.env = %q", got)
+	}
+	if got := git(t, projPath, "ls-files", templateManifestName); got != "" {
+		t.Errorf("manifest copied into the project")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import "time"
+
+type phaseTiming struct {
+	name string
+	duration time.Duration
+}
+
+// phaseMetrics records how long each phase of a run took for --metrics.
+// Disabled metrics still run the phases, they just do not time them.
+type phaseMetrics struct {
+	enabled bool
+	phases []phaseTiming
+}
+
+func (m *phaseMetrics) measure(name string, phase func()) {
+	if !m.enabled {
+		phase()
+		return
+	}
+
+	start := time.Now()
+	phase()
+	m.phases = append(m.phases, phaseTiming{name, time.Since(start)})
+}
+
+func (m *phaseMetrics) print() {
+	if !m.enabled {
+		return
+	}
+
+	total := time.Duration(0)
+	output.step("Metrics:\n")
+	for _, p := range m.phases {
+		output.step("   %-12s %v\n", p.name, p.duration.Round(time.Millisecond))
+		total += p.duration
+	}
+	output.step("   %-12s %v\n", "total", total.Round(time.Millisecond))
+}
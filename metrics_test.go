@@ -0,0 +1,45 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhaseMetrics(t *testing.T) {
+	logged := captureOutput(t)
+
+	m := &phaseMetrics{}
+	ran := false
+	m.measure("create", func() { ran = true })
+	m.print()
+	if !ran || len(m.phases) != 0 || logged.Len() != 0 {
+		t.Errorf("disabled metrics: ran %v, phases %v, output %q", ran, m.phases, logged)
+	}
+
+	m = &phaseMetrics{enabled: true}
+	m.measure("clone", func() { time.Sleep(5 * time.Millisecond) })
+	m.print()
+	if len(m.phases) != 1 || m.phases[0].name != "clone" || m.phases[0].duration < 5 * time.Millisecond {
+		t.Errorf("phases = %v", m.phases)
+	}
+}
+
+func TestMetricsOutput(t *testing.T) {
+	newTestEnv(t)
+	fakeSsh(t, "Hi octocat! You've successfully authenticated, but GitHub does not provide shell access.")
+
+	out := mustRun(t, "", "", "--metrics", "--verify-ssh", "timed")
+
+	_, block, ok := strings.Cut(out.stdout, "Metrics:\n")
+	if !ok {
+		t.Fatalf("no metrics block:\n%s", out.stdout)
+	}
+	for _, phase := range []string{"auth check", "fetch", "create", "clone", "scaffold", "commit", "push", "total"} {
+		line := regexp.MustCompile(`(?m)^   ` + phase + ` +[0-9.]+(ns|µs|ms|s)$`)
+		if !line.MatchString(block) {
+			t.Errorf("no duration for %s:\n%s", phase, block)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+)
+
+type runResult struct {
+	projectResult
+	ok bool
+	err string
+}
+
+// lastLine returns the last non-empty line of s, which for a failed run is
+// the message it exited with. A run with --json prints it as json object.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	line := strings.TrimSpace(lines[len(lines) - 1])
+
+	message := struct{ Message string }{}
+	if json.Unmarshal([]byte(line), &message) == nil && message.Message != "" {
+		return message.Message
+	}
+	return line
+}
+
+// resultFileEnv names the file a run started by runMany writes its result
+// to, so the summary shows the name the run ended up using, e.g. with
+// --auto-suffix, instead of the one asked for.
+const resultFileEnv = "CREATE_PROJECT_RESULT_FILE"
+
+// writeResultFile hands result to the runMany that started this run, if
+// any.
+func writeResultFile(result projectResult) {
+	p := os.Getenv(resultFileEnv)
+	if p == "" {
+		return
+	}
+
+	data, err := json.Marshal(result)
+	iferr("Failed to encode result: %v\n", err)
+	err = os.WriteFile(p, data, 0600)
+	iferr("Failed to write result file: %v\n", err)
+}
+
+// runMany creates each project in its own process, so a failure exiting
+// one run does not stop the rest, and reports a summary of all of them.
+func runMany(opts *appOptions, config *appConfig) []runResult {
+	exe, err := os.Executable()
+	iferr("Failed to locate executable: %v\n", err)
+
+	results := []runResult{}
+	for _, name := range opts.projNames {
+		output.step("==> %s\n", name)
+
+		stderr := bytes.Buffer{}
+		cmd := exec.CommandContext(runCtx, exe, append(append([]string{}, opts.flagArgs...), name)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = output.stdout
+		cmd.Stderr = io.MultiWriter(output.stderr, &stderr)
+
+		resultFile, err := os.CreateTemp("", "create-project-result-*.json")
+		iferr("Failed to create result file: %v\n", err)
+		resultFile.Close()
+		cmd.Env = append(os.Environ(), resultFileEnv + "=" + resultFile.Name())
+
+		result := runResult{projectResult: newProjectResult(opts.owner, name, "", config)}
+		if err := cmd.Run(); err != nil {
+			result.err = lastLine(stderr.String())
+			if result.err == "" {
+				result.err = err.Error()
+			}
+		} else {
+			result.ok = true
+			// A run that resumed a push has no result to hand back.
+			if data, err := os.ReadFile(resultFile.Name()); err == nil && len(data) > 0 {
+				json.Unmarshal(data, &result.projectResult)
+			}
+		}
+		os.Remove(resultFile.Name())
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func printRunSummary(results []runResult) int {
+	failed := 0
+
+	output.step("\n")
+	// The table is what a run of many projects reports, so it is printed
+	// even when quiet.
+	table := bytes.Buffer{}
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tREPOSITORY\tERROR")
+	for _, r := range results {
+		status := "ok"
+		if !r.ok {
+			status = "failed"
+			failed++
+		}
+		repoUrl := r.RepoUrl
+		if !r.ok {
+			repoUrl = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, status, repoUrl, r.err)
+	}
+	w.Flush()
+
+	output.result("%s", table.String())
+	output.result("%d succeeded, %d failed\n", len(results) - failed, failed)
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMultiProjectSummary(t *testing.T) {
+	env := newTestEnv(t)
+	writeFile(t, filepath.Join(env.projPath("taken"), "README.md"), "# Taken\n")
+
+	out := runMain(t, "", "y\ny\ny\n", "first", "taken", "second")
+	if out.code != 1 {
+		t.Fatalf("exit %d, want 1 with a failed project\n%s", out.code, out.stderr)
+	}
+
+	rows := map[string]*regexp.Regexp{
+		"first": regexp.MustCompile(`(?m)^first +ok +https://github\.com/octocat/first +$`),
+		"taken": regexp.MustCompile(`(?m)^taken +failed +- +Failed to clone repository: .*already exists and is not an empty directory\.$`),
+		"second": regexp.MustCompile(`(?m)^second +ok +https://github\.com/octocat/second +$`),
+	}
+	for name, row := range rows {
+		if !row.MatchString(out.stdout) {
+			t.Errorf("no summary row for %s:\n%s", name, out.stdout)
+		}
+	}
+	if !strings.Contains(out.stdout, "2 succeeded, 1 failed\n") {
+		t.Errorf("no final count:\n%s", out.stdout)
+	}
+
+	for _, name := range []string{"first", "second"} {
+		if got := git(t, env.projPath(name), "log", "--format=%s"); got != "initial commit" {
+			t.Errorf("%s not created: %q", name, got)
+		}
+	}
+}
+
+func TestMultiProjectSummaryOnly(t *testing.T) {
+	env := newTestEnv(t)
+	writeFile(t, filepath.Join(env.projPath("taken"), "README.md"), "# Taken\n")
+
+	out := runMain(t, "", "", "--summary-only", "first", "taken")
+	if out.code != 1 {
+		t.Fatalf("exit %d, want 1 with a failed project\n%s", out.code, out.stderr)
+	}
+	if strings.Contains(out.stdout, "==> ") {
+		t.Errorf("progress printed with --summary-only:\n%s", out.stdout)
+	}
+	if !regexp.MustCompile(`(?m)^NAME +STATUS +REPOSITORY +ERROR$`).MatchString(out.stdout) ||
+		!regexp.MustCompile(`(?m)^taken +failed +- +Failed to clone repository`).MatchString(out.stdout) {
+		t.Errorf("no summary table:\n%s", out.stdout)
+	}
+	if !strings.HasSuffix(out.stdout, "1 succeeded, 1 failed\n") {
+		t.Errorf("no final count:\n%s", out.stdout)
+	}
+}
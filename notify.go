@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type projectResult struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	RepoUrl string `json:"repo_url"`
+}
+
+func newProjectResult(owner string, projName string, projPath string, config *appConfig) projectResult {
+	return projectResult{
+		Name: projName,
+		Path: projPath,
+		RepoUrl: fmt.Sprintf("https://%s/%s/%s", config.gitHost(), owner, projName),
+	}
+}
+
+// runNotifyCommand runs command through the shell with the result json on
+// stdin and the main fields exported as CREATE_PROJECT_* variables.
+func runNotifyCommand(command string, result projectResult) {
+	data, err := json.Marshal(result)
+	iferr("Failed to encode result: %v\n", err)
+
+	cmd := exec.CommandContext(runCtx, "/bin/sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = output.progress()
+	cmd.Stderr = output.raw()
+	cmd.Env = append(
+		os.Environ(),
+		"CREATE_PROJECT_NAME=" + result.Name,
+		"CREATE_PROJECT_PATH=" + result.Path,
+		"CREATE_PROJECT_REPO_URL=" + result.RepoUrl,
+	)
+
+	if err := cmd.Run(); err != nil {
+		output.warn("notify command failed: %v\n", err)
+	}
+}
+
+func postNotifyWebhook(url string, result projectResult) {
+	data, err := json.Marshal(result)
+	iferr("Failed to encode result: %v\n", err)
+
+	req, err := http.NewRequestWithContext(runCtx, http.MethodPost, url, bytes.NewReader(data))
+	iferr("Failed to create request: %v\n", err)
+
+	req.Header.Add("User-Agent", "Go")
+	req.Header.Add("Content-Type", "application/json")
+
+	client := http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		output.warn("notify webhook failed: %v\n", err)
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		output.warn("notify webhook responded with %s\n", res.Status)
+	}
+}
+
+func notify(opts *appOptions, result projectResult) {
+	if strings.TrimSpace(opts.notifyCommand) != "" {
+		runNotifyCommand(opts.notifyCommand, result)
+	}
+	if opts.notifyWebhook != "" {
+		postNotifyWebhook(opts.notifyWebhook, result)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotifyWebhook(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /hook", http.StatusNoContent, "")
+	mustRun(t, "", "", "--notify-webhook", env.api.URL + "/hook", "notified")
+
+	hooks := env.api.received("POST", "/hook")
+	if len(hooks) != 1 {
+		t.Fatalf("webhook got %d requests, want 1", len(hooks))
+	}
+	if ct := hooks[0].Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	want := map[string]any{
+		"name": "notified",
+		"path": env.projPath("notified"),
+		"repo_url": "https://github.com/" + testUser + "/notified",
+	}
+	body := hooks[0].json(t)
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+}
+
+func TestNotifyCommand(t *testing.T) {
+	env := newTestEnv(t)
+	resultPath := filepath.Join(env.dir, "result.json")
+	mustRun(t, "", "", "--notify-command", `cat > "` + resultPath + `"; echo "$CREATE_PROJECT_NAME" >> "` + resultPath + `.name"`, "notified")
+
+	result := projectResult{}
+	if err := json.Unmarshal([]byte(readFile(t, resultPath)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Name != "notified" || result.Path != env.projPath("notified") {
+		t.Errorf("command got result %+v", result)
+	}
+	if name := readFile(t, resultPath + ".name"); name != "notified\n" {
+		t.Errorf("CREATE_PROJECT_NAME = %q", name)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type runPlan struct {
+	Options map[string]any `json:"options"`
+	Steps []string `json:"steps"`
+}
+
+func buildPlan(projPath string, config *appConfig, opts *appOptions) runPlan {
+	branch := opts.branch
+	if branch == "" {
+		branch = "main"
+		if opts.githubInit {
+			branch = "(remote default)"
+		}
+	}
+
+	variables := []string{}
+	for _, v := range opts.variables {
+		variables = append(variables, v[0])
+	}
+	// Only the names of secrets, a plan may end up in logs.
+	secrets := []string{}
+	for _, s := range opts.secrets {
+		secrets = append(secrets, s[0])
+	}
+
+	options := map[string]any{
+		"name": opts.projName,
+		"auto_suffix": opts.autoSuffix,
+		"owner": opts.owner,
+		"owner_type": opts.ownerType,
+		"owner_fallback": opts.ownerFallback,
+		"host": config.host,
+		"clone_host": config.gitHost(),
+		"retry_auth": opts.retryAuth,
+		"local_first": opts.localFirst,
+		"path": projPath,
+		"branch": branch,
+		"title": opts.projectTitle(),
+		"description": opts.description,
+		"templates": opts.templates,
+		"dirs": opts.dirs,
+		"module_path": opts.modulePath,
+		"with_tests": opts.withTests,
+		"format": opts.format,
+		"vscode": opts.vscode,
+		"makefile": opts.makefile,
+		"template_git": opts.templateGit,
+		"template_url": opts.templateUrl,
+		"env_file": opts.envFile,
+		"license": opts.license,
+		"notice": opts.notice,
+		"readme_license_section": opts.readmeLicenseSection,
+		"github_init": opts.githubInit,
+		"gitignore_template": opts.gitignoreTemplate,
+		"gitignore_header": config.gitignoreHeader,
+		"git_hooks": opts.gitHooks,
+		"mailmap": opts.mailmap,
+		"authors": opts.authors,
+		"changelog": opts.changelog,
+		"funding": opts.funding,
+		"version_file": opts.versionFile,
+		"no_default_files": opts.noDefaultFiles,
+		"signoff": opts.signoff,
+		"fix_identity": opts.fixIdentity,
+		"grouped_commits": opts.groupedCommits,
+		"delete_on_empty_push": opts.deleteOnEmptyPush,
+		"add": opts.addPatterns,
+		"co_authors": opts.coAuthors,
+		"since": opts.since.String(),
+		"timeout": opts.timeout.String(),
+		"verify_ssh": opts.verifySsh,
+		"metrics": opts.metrics,
+		"dir_permissions_check": opts.dirPermissionsCheck,
+		"summary_only": opts.summaryOnly,
+		"json": opts.json,
+		"color": opts.color,
+		"log_file": opts.logFile,
+		"edit": opts.edit,
+		"trace": opts.trace,
+		"tls_min_version": tlsVersionName(config.tlsMinVersion),
+		"prune_default_labels": opts.pruneLabels,
+		"as_template": opts.asTemplate,
+		"ruleset": opts.ruleset,
+		"deploy_key": opts.deployKey,
+		"deploy_key_write": opts.deployKeyWrite,
+		"merge_settings": config.mergeSettings,
+		"api_fields": opts.apiFields,
+		"variables": variables,
+		"secrets": secrets,
+		"notify_command": opts.notifyCommand,
+		"notify_webhook": opts.notifyWebhook,
+		"max_retries": config.retry.perOperation,
+		"max_retries_total": config.retry.remaining,
+		"retry_on": config.retry.retryOn,
+		"retry_jitter": config.retry.jitter,
+	}
+
+	steps := []string{}
+	if opts.verifySsh {
+		steps = append(steps, "verify ssh access")
+	}
+	if fetchesLicense(opts) {
+		steps = append(steps, "fetch license " + opts.license)
+	}
+	if opts.gitignoreTemplate != "" && !opts.githubInit {
+		steps = append(steps, "fetch gitignore template " + opts.gitignoreTemplate)
+	}
+	if opts.templateSource() != "" {
+		steps = append(steps, "fetch template repository " + opts.templateSource())
+	}
+	create := fmt.Sprintf("create repository %s/%s", opts.owner, opts.projName)
+	if opts.githubInit {
+		files := []string{"README.md"}
+		if opts.gitignoreTemplate != "" {
+			files = append(files, ".gitignore")
+		}
+		if opts.license != "" {
+			files = append(files, "LICENSE")
+		}
+		create += " with " + strings.Join(files, ", ")
+	}
+	if opts.since > 0 {
+		create += fmt.Sprintf(" unless created within %v", opts.since)
+	}
+	remote := []string{create}
+	if opts.pruneLabels {
+		remote = append(remote, "delete default labels")
+	}
+	if opts.asTemplate {
+		remote = append(remote, "mark repository as template")
+	}
+	if opts.ruleset != "" {
+		remote = append(remote, "create ruleset from " + opts.ruleset)
+	}
+	for _, v := range variables {
+		remote = append(remote, "set actions variable " + v)
+	}
+	for _, s := range secrets {
+		remote = append(remote, "set actions secret " + s)
+	}
+	if opts.deployKey != "" {
+		remote = append(remote, "add deploy key " + opts.deployKey)
+	}
+	if opts.localFirst {
+		steps = append(steps, "initialize repository in " + projPath + " on " + branch)
+	} else {
+		steps = append(steps, remote...)
+		steps = append(steps, "clone repository into " + projPath)
+		if opts.branch != "" || !opts.githubInit {
+			steps = append(steps, "rename local branch to " + branch)
+		}
+	}
+	switch {
+	case opts.githubInit:
+	case opts.noDefaultFiles:
+		if opts.gitignoreTemplate != "" {
+			steps = append(steps, "create .gitignore")
+		}
+	default:
+		steps = append(steps, "create README.md and .gitignore")
+	}
+	if opts.license != "" && !opts.githubInit {
+		steps = append(steps, "create LICENSE")
+	}
+	if opts.notice {
+		steps = append(steps, "create NOTICE")
+	}
+	if opts.readmeLicenseSection {
+		steps = append(steps, "add License section to README.md")
+	}
+	for _, t := range opts.templates {
+		steps = append(steps, "apply " + t + " template")
+	}
+	if opts.format {
+		for _, t := range opts.templates {
+			if formatter, ok := templateFormatters[t]; ok {
+				steps = append(steps, "format " + t + " template with " + formatter[0])
+			}
+		}
+	}
+	if opts.templateSource() != "" {
+		steps = append(steps, "copy template repository files")
+	}
+	if opts.makefile {
+		steps = append(steps, "create Makefile")
+	}
+	if opts.vscode {
+		steps = append(steps, "create .vscode settings")
+	}
+	if len(opts.dirs) > 0 {
+		steps = append(steps, "create dirs " + strings.Join(opts.dirs, ", "))
+	}
+	if opts.mailmap {
+		steps = append(steps, "create .mailmap")
+	}
+	if opts.authors {
+		steps = append(steps, "create AUTHORS")
+	}
+	if opts.changelog {
+		steps = append(steps, "create CHANGELOG.md")
+	}
+	if opts.versionFile != "" {
+		steps = append(steps, "create VERSION")
+	}
+	if len(opts.funding) > 0 {
+		steps = append(steps, "create .github/FUNDING.yml")
+	}
+	if opts.gitHooks {
+		steps = append(steps, "install git hooks")
+	}
+	if opts.groupedCommits {
+		steps = append(steps, "commit docs, license and ci files separately")
+	}
+	switch {
+	case opts.githubInit:
+		steps = append(steps, "commit and push changes to " + branch + " if any")
+	case opts.localFirst:
+		steps = append(steps, "commit changes")
+		steps = append(steps, remote...)
+		steps = append(steps, "add origin remote", "push to " + branch)
+	default:
+		steps = append(steps, "commit changes", "push to " + branch)
+	}
+	steps = append(steps, "record project in registry")
+	if opts.notifyCommand != "" {
+		steps = append(steps, "run notify command")
+	}
+	if opts.notifyWebhook != "" {
+		steps = append(steps, "post notify webhook")
+	}
+	if opts.edit {
+		steps = append(steps, "open project in editor")
+	}
+
+	return runPlan{Options: options, Steps: steps}
+}
+
+func printPlan(plan runPlan, format string) {
+	if format == "json" {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		iferr("Failed to encode plan: %v\n", err)
+		output.result("%s\n", data)
+		return
+	}
+
+	keys := []string{}
+	for k := range plan.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	output.step("Options:\n")
+	for _, k := range keys {
+		v, err := json.Marshal(plan.Options[k])
+		iferr("Failed to encode plan: %v\n", err)
+		output.step("   %-20s %s\n", k, v)
+	}
+
+	output.step("Steps:\n")
+	for i, step := range plan.Steps {
+		output.step("   %d. %s\n", i + 1, step)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPrintPlan(t *testing.T) {
+	env := newTestEnv(t)
+	out := mustRun(t, "", "",
+		"--dry-run", "--print-plan", "json",
+		"--license", "MIT", "--template", "go", "--branch", "trunk",
+		"--description", "A tool", "--prune-default-labels", "--variable", "REGION=eu",
+		"planned")
+
+	plan := runPlan{}
+	if err := json.Unmarshal([]byte(out.stdout), &plan); err != nil {
+		t.Fatalf("plan is not json: %v\n%s", err, out.stdout)
+	}
+
+	options := map[string]any{
+		"name": "planned",
+		"owner": testUser,
+		"owner_type": "user",
+		"description": "A tool",
+		"license": "MIT",
+		"branch": "trunk",
+	}
+	for k, v := range options {
+		if plan.Options[k] != v {
+			t.Errorf("option %s = %#v, want %#v", k, plan.Options[k], v)
+		}
+	}
+	if templates, _ := plan.Options["templates"].([]any); len(templates) != 1 || templates[0] != "go" {
+		t.Errorf("option templates = %#v", plan.Options["templates"])
+	}
+
+	projPath := env.projPath("planned")
+	steps := []string{
+		"fetch license MIT",
+		"create repository " + testUser + "/planned",
+		"delete default labels",
+		"set actions variable REGION",
+		"clone repository into " + projPath,
+		"rename local branch to trunk",
+		"create README.md and .gitignore",
+		"create LICENSE",
+		"apply go template",
+		"commit changes",
+		"push to trunk",
+		"record project in registry",
+	}
+	last := -1
+	for _, step := range steps {
+		i := slices.Index(plan.Steps, step)
+		if i < 0 {
+			t.Errorf("plan lacks step %q", step)
+			continue
+		}
+		if i < last {
+			t.Errorf("step %q out of order", step)
+		}
+		last = i
+	}
+
+	if len(env.api.received("POST", ".*")) > 0 {
+		t.Errorf("dry run created something")
+	}
+}
+
+func TestPrintPlanText(t *testing.T) {
+	newTestEnv(t)
+	out := mustRun(t, "", "", "--dry-run", "--print-plan", "text", "planned")
+
+	for _, want := range []string{"name", "planned", "create repository " + testUser + "/planned", "push to main"} {
+		if !strings.Contains(out.stdout, want) {
+			t.Errorf("text plan lacks %q:\n%s", want, out.stdout)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// presetName returns NAME for a `[preset NAME]` config key.
+func presetName(key string) (string, bool) {
+	if !strings.HasPrefix(key, "[preset ") || !strings.HasSuffix(key, "]") {
+		return "", false
+	}
+	name := strings.TrimSpace(key[len("[preset ") : len(key) - 1])
+	return name, name != ""
+}
+
+func loadPresets() map[string]string {
+	presets := map[string]string{}
+
+	f, err := os.Open(getConfigPath())
+	iferr("Failed to open config file: %v\n", err)
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		k, v, ok := strings.Cut(stripBom(s.Text()), "=")
+		if !ok {
+			continue
+		}
+		if name, ok := presetName(strings.TrimSpace(k)); ok {
+			presets[name] = strings.TrimSpace(v)
+		}
+	}
+	iferr("Failed to read config file: %v\n", s.Err())
+
+	return presets
+}
+
+// tokenize splits s on whitespace, keeping single or double quoted parts
+// together. A backslash escapes the next character.
+func tokenize(s string) []string {
+	args := []string{}
+	arg := strings.Builder{}
+	inArg := false
+	escaped := false
+	var quote rune
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		output.error("Unterminated quote in preset: %s\n", s)
+		os.Exit(1)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args
+}
+
+// splitArgs splits a preset value into arguments. The value is usually
+// quoted as a whole, in which case its content is split again.
+func splitArgs(s string) []string {
+	s = strings.TrimSpace(s)
+
+	args := tokenize(s)
+	if len(args) == 1 && strings.HasPrefix(s, "\"") {
+		args = tokenize(args[0])
+	}
+
+	return args
+}
+
+// expandPresets replaces every `--preset NAME` in args with the options the
+// config defines for NAME, before the arguments are parsed.
+func expandPresets(args []string) []string {
+	var presets map[string]string
+	expanded := []string{}
+
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--preset" {
+			expanded = append(expanded, args[i])
+			continue
+		}
+
+		name := nextArg(args, &i)
+		if presets == nil {
+			presets = loadPresets()
+		}
+
+		preset, ok := presets[name]
+		if !ok {
+			output.error("Unknown preset: %s\n", name)
+			os.Exit(1)
+		}
+		expanded = append(expanded, splitArgs(preset)...)
+	}
+
+	return expanded
+}
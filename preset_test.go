@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandPresets(t *testing.T) {
+	newTestEnv(t,
+		`[preset oss] = "--license mit --mailmap --description 'An open source tool'"`,
+		"[preset go] = --template go --with-tests",
+	)
+
+	args := expandPresets([]string{"--preset", "oss", "--preset", "go", "--owner", "acme", "tool"})
+	want := []string{"--license", "mit", "--mailmap", "--description", "An open source tool", "--template", "go", "--with-tests", "--owner", "acme", "tool"}
+	if !slices.Equal(args, want) {
+		t.Fatalf("expanded to %q, want %q", args, want)
+	}
+
+	opts := parseArgs(args)
+	if opts.license != "mit" || !opts.mailmap || opts.description != "An open source tool" || !opts.withTests || !slices.Equal(opts.templates, []string{"go"}) {
+		t.Errorf("options not set from presets: %+v", opts)
+	}
+}
+
+func TestUnknownPreset(t *testing.T) {
+	newTestEnv(t, "[preset oss] = --license mit")
+
+	out := runMain(t, "", "", "--preset", "closed", "tool")
+	if out.code != 1 || !strings.Contains(out.stderr, "Unknown preset: closed") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in string
+		want []string
+	}{
+		{`--a b`, []string{"--a", "b"}},
+		{`"--a 'b c'"`, []string{"--a", "b c"}},
+		{`--a "b c" d\ e`, []string{"--a", "b c", "d e"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitArgs(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// printer is what all output of a run goes through. Progress is dropped
+// when quiet, results like the --summary-only line and prompts always
+// reach stdout and warnings and errors go to stderr. With json every step,
+// warning and error is printed as one {"level", "message"} object per line,
+// with color warnings and errors are highlighted and with log everything,
+// progress dropped by quiet included, is also written to the log file.
+type printer struct {
+	stdout io.Writer
+	stderr io.Writer
+	quiet bool
+	json bool
+	color bool
+	log io.Writer
+}
+
+var output = &printer{stdout: os.Stdout, stderr: os.Stderr}
+
+var levelColors = map[string]string{
+	"warning": "\x1b[33m",
+	"error": "\x1b[31m",
+}
+
+// useColor tells whether --color mode highlights output. auto only does
+// when stderr is a terminal and NO_COLOR is unset.
+func useColor(mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode() & os.ModeCharDevice != 0
+}
+
+// setup applies --json, --color and --log-file to p.
+func (p *printer) setup(opts *appOptions) {
+	p.json = opts.json
+	p.color = !opts.json && useColor(opts.color)
+
+	if opts.logFile != "" {
+		f, err := os.OpenFile(opts.logFile, os.O_WRONLY | os.O_CREATE | os.O_APPEND, 0600)
+		iferr("Failed to open log file: %v\n", err)
+		p.log = f
+	}
+}
+
+// progress is the writer for step by step output.
+func (p *printer) progress() io.Writer {
+	if p.quiet {
+		return io.Discard
+	}
+	return p.stdout
+}
+
+// raw is the writer for output printed as is, like --trace and what git
+// prints to stderr.
+func (p *printer) raw() io.Writer {
+	if p.log != nil {
+		return io.MultiWriter(p.stderr, p.log)
+	}
+	return p.stderr
+}
+
+// print writes msg to w the way the mode asks for. A message starting with
+// \r redraws the current line, like download progress, and only makes sense
+// on a terminal, so it is neither logged nor printed as json.
+func (p *printer) print(w io.Writer, level string, prefix string, msg string) {
+	live := strings.HasPrefix(msg, "\r")
+	if p.log != nil && !live {
+		fmt.Fprint(p.log, prefix + msg)
+	}
+
+	switch {
+	case p.json:
+		msg = strings.TrimSpace(msg)
+		if live || msg == "" {
+			return
+		}
+		// A map of strings always encodes.
+		data, _ := json.Marshal(map[string]string{"level": level, "message": prefix + msg})
+		fmt.Fprintf(w, "%s\n", data)
+	case p.color && levelColors[level] != "":
+		text := strings.TrimRight(msg, "\n")
+		fmt.Fprintf(w, "%s%s%s\x1b[0m%s", levelColors[level], prefix, text, msg[len(text):])
+	default:
+		fmt.Fprint(w, prefix + msg)
+	}
+}
+
+func (p *printer) step(format string, a ...any) {
+	p.print(p.progress(), "step", "", fmt.Sprintf(format, a...))
+}
+
+// result is printed as is even with json, it is what the run was asked to
+// print, like a json plan or a prompt.
+func (p *printer) result(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	if p.log != nil {
+		fmt.Fprint(p.log, msg)
+	}
+	fmt.Fprint(p.stdout, msg)
+}
+
+func (p *printer) warn(format string, a ...any) {
+	p.print(p.stderr, "warning", "Warning: ", fmt.Sprintf(format, a...))
+}
+
+func (p *printer) error(format string, a ...any) {
+	p.print(p.stderr, "error", "", fmt.Sprintf(format, a...))
+}
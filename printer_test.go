@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testPrinter prints every level once through p.
+func testPrinter(p *printer) {
+	p.step("Creating %s...\n", "demo")
+	p.step("\rDownloaded 1.0 MiB")
+	p.result("created %s\n", "demo")
+	p.warn("no %s\n", "license")
+	p.error("Failed to push: %s\n", "denied")
+}
+
+func TestPrinterModes(t *testing.T) {
+	tests := []struct {
+		name string
+		p printer
+		stdout string
+		stderr string
+	}{
+		{
+			"plain",
+			printer{},
+			"Creating demo...\n\rDownloaded 1.0 MiBcreated demo\n",
+			"Warning: no license\nFailed to push: denied\n",
+		},
+		{
+			"quiet",
+			printer{quiet: true},
+			"created demo\n",
+			"Warning: no license\nFailed to push: denied\n",
+		},
+		{
+			"json",
+			printer{json: true},
+			`{"level":"step","message":"Creating demo..."}` + "\ncreated demo\n",
+			`{"level":"warning","message":"Warning: no license"}` + "\n" + `{"level":"error","message":"Failed to push: denied"}` + "\n",
+		},
+		{
+			"color",
+			printer{color: true},
+			"Creating demo...\n\rDownloaded 1.0 MiBcreated demo\n",
+			"\x1b[33mWarning: no license\x1b[0m\n\x1b[31mFailed to push: denied\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := bytes.Buffer{}
+			stderr := bytes.Buffer{}
+			p := tt.p
+			p.stdout = &stdout
+			p.stderr = &stderr
+
+			testPrinter(&p)
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout %q, want %q", stdout.String(), tt.stdout)
+			}
+			if stderr.String() != tt.stderr {
+				t.Errorf("stderr %q, want %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
+
+func TestPrinterLog(t *testing.T) {
+	stdout := bytes.Buffer{}
+	logged := bytes.Buffer{}
+	p := printer{stdout: &stdout, stderr: &stdout, quiet: true, color: true, log: &logged}
+
+	testPrinter(&p)
+	p.raw().Write([]byte("> GET /user\n"))
+
+	want := "Creating demo...\ncreated demo\nWarning: no license\nFailed to push: denied\n> GET /user\n"
+	if logged.String() != want {
+		t.Errorf("logged %q, want %q", logged.String(), want)
+	}
+	if strings.Contains(stdout.String(), "Creating demo") {
+		t.Errorf("quiet step printed: %q", stdout.String())
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if !useColor("always") || useColor("never") {
+		t.Errorf("always and never not respected")
+	}
+	// Test output is never a terminal.
+	if useColor("auto") {
+		t.Errorf("auto colors output that is not a terminal")
+	}
+}
+
+func TestInvalidColor(t *testing.T) {
+	newTestEnv(t)
+
+	out := runMain(t, "", "", "--color", "sometimes", "demo")
+	if out.code != 1 || !strings.HasPrefix(out.stderr, "Invalid color, expected auto, always or never: sometimes\n") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestJsonAndLogFile(t *testing.T) {
+	env := newTestEnv(t)
+	logPath := filepath.Join(env.dir, "run.log")
+
+	out := mustRun(t, "", "", "--json", "--color", "always", "--log-file", logPath, "logged")
+	for _, line := range strings.Split(strings.TrimSpace(out.stdout), "\n") {
+		if strings.HasPrefix(line, "Create project ") {
+			continue
+		}
+		message := map[string]string{}
+		if err := json.Unmarshal([]byte(line), &message); err != nil || message["level"] != "step" {
+			t.Errorf("stdout line is not a json step: %q", line)
+		}
+	}
+	if !strings.Contains(out.stdout, `{"level":"step","message":"Committing changes to the repository..."}`) {
+		t.Errorf("no commit step:\n%s", out.stdout)
+	}
+	if strings.Contains(out.stdout + out.stderr, "\x1b[") {
+		t.Errorf("json output colored")
+	}
+
+	logged := readFile(t, logPath)
+	if !strings.Contains(logged, "Loading config file...\n") || !strings.Contains(logged, "Create project " + env.projPath("logged") + " (Y/n)\n") {
+		t.Errorf("log misses output of the run:\n%s", logged)
+	}
+
+	out = runMain(t, "", "", "--log-file", logPath, "--summary-only", "bad..name?")
+	if out.code != 1 {
+		t.Fatalf("exit %d", out.code)
+	}
+	mustRun(t, "", "", "--log-file", logPath, "--summary-only", "quiet")
+	logged = readFile(t, logPath)
+	if !strings.Contains(logged, "Loading config file...\n") || strings.Count(logged, "Loading config file...\n") != 2 {
+		t.Errorf("log not appended to or misses steps hidden by --summary-only:\n%s", logged)
+	}
+}
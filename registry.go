@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// The registry keeps one tab separated line per created project:
+// created-at, path and repository url.
+
+func getRegistryPath() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "projects")
+}
+
+func appendToRegistry(result projectResult) {
+	registryPath := getRegistryPath()
+
+	err := os.MkdirAll(filepath.Dir(registryPath), 0700)
+	iferr("Failed to create config folder: %v\n", err)
+
+	f, err := os.OpenFile(registryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	iferr("Failed to open project registry: %v\n", err)
+	defer f.Close()
+
+	_, err = fmt.Fprintf(
+		f,
+		"%s\t%s\t%s\n",
+		time.Now().Format(time.RFC3339),
+		result.Path,
+		result.RepoUrl,
+	)
+	iferr("Failed to write project registry: %v\n", err)
+}
+
+func listRegistry() {
+	f, err := os.Open(getRegistryPath())
+	if os.IsNotExist(err) {
+		output.step("No projects created yet\n")
+		return
+	}
+	iferr("Failed to open project registry: %v\n", err)
+	defer f.Close()
+
+	w := tabwriter.NewWriter(output.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CREATED\tPATH\tREPOSITORY")
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Split(s.Text(), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	iferr("Failed to read project registry: %v\n", s.Err())
+
+	w.Flush()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "first")
+	mustRun(t, "", "", "second")
+
+	registry := readFile(t, filepath.Join(filepath.Dir(env.configPath), "projects"))
+	lines := strings.Split(strings.TrimSuffix(registry, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("registry has %d entries, want 2:\n%s", len(lines), registry)
+	}
+
+	fields := strings.Split(lines[0], "\t")
+	if len(fields) != 3 {
+		t.Fatalf("entry %q has %d fields, want 3", lines[0], len(fields))
+	}
+	if _, err := time.Parse(time.RFC3339, fields[0]); err != nil {
+		t.Errorf("created-at: %v", err)
+	}
+	if fields[1] != env.projPath("first") || fields[2] != "https://github.com/" + testUser + "/first" {
+		t.Errorf("entry = %q", lines[0])
+	}
+
+	out := mustRun(t, "", "", "--list")
+	listed := strings.Split(strings.TrimSuffix(out.stdout, "\n"), "\n")
+	if len(listed) != 3 || !strings.HasPrefix(listed[0], "CREATED") {
+		t.Fatalf("--list printed:\n%s", out.stdout)
+	}
+	for i, name := range []string{"first", "second"} {
+		if !strings.Contains(listed[i + 1], env.projPath(name)) {
+			t.Errorf("--list line %d = %q, want %s", i + 1, listed[i + 1], name)
+		}
+	}
+}
+
+func TestRegistryListEmpty(t *testing.T) {
+	newTestEnv(t)
+	out := mustRun(t, "", "", "--list")
+	if !strings.Contains(out.stdout, "No projects created yet") {
+		t.Errorf("--list printed %q", out.stdout)
+	}
+}
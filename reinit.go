@@ -0,0 +1,165 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// copyMissingFiles copies every file of src that does not exist in dst and
+// returns the copied paths relative to dst. Symlinks are copied as links,
+// a link in dst counts as existing even when it does not resolve.
+func copyMissingFiles(src string, dst string) []string {
+	copied := []string{}
+
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+		if _, err := os.Lstat(target); err == nil {
+			return nil
+		}
+
+		if d.Type() & fs.ModeSymlink != 0 {
+			err = copyLink(p, target)
+		} else {
+			err = copyFile(p, target)
+		}
+		if err != nil {
+			return err
+		}
+		copied = append(copied, rel)
+		return nil
+	})
+	iferr("Failed to copy project files: %v\n", err)
+
+	return copied
+}
+
+func copyFile(src string, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func copyLink(src string, dst string) error {
+	link, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(link, dst)
+}
+
+// commitMissingFiles scaffolds into a scratch repository, moves over only
+// the files projPath is missing and commits them. It reports false when
+// projPath already has them all.
+func commitMissingFiles(
+	projName string,
+	projPath string,
+	assets *projectAssets,
+	config *appConfig,
+	opts *appOptions,
+) bool {
+	tmp, err := os.MkdirTemp("", "create-project-")
+	iferr("Failed to create temp dir: %v\n", err)
+	defer os.RemoveAll(tmp)
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", "init", "-q", tmp)
+	err = cmd.Run()
+	iferr("Failed to init scratch repository: %v\n", err)
+
+	scaffoldProject(projName, tmp, assets, config, opts)
+
+	added := copyMissingFiles(tmp, projPath)
+	if len(added) == 0 {
+		return false
+	}
+
+	if opts.gitHooks {
+		setHooksPath(projPath)
+	}
+
+	for _, f := range added {
+		output.step("Adding %s\n", f)
+	}
+
+	cmd = exec.CommandContext(runCtx, "/bin/git", append([]string{"add", "--"}, added...)...)
+	cmd.Dir = projPath
+	err = cmd.Run()
+	iferr("Failed to add changes: %v\n", err)
+
+	commitArgs := []string{"commit", "-m", commitMessage("add missing project files", opts)}
+	if opts.signoff {
+		commitArgs = append(commitArgs, "-s")
+	}
+	commitArgs = append(append(commitArgs, "--"), added...)
+
+	cmd = exec.CommandContext(runCtx, "/bin/git", commitArgs...)
+	cmd.Dir = projPath
+	err = cmd.Run()
+	iferr("Failed to commit changes: %v\n", err)
+
+	return true
+}
+
+// reinitProject adds the files projPath is missing and pushes them, leaving
+// the remote repository as it is.
+func reinitProject(
+	projName string,
+	projPath string,
+	assets *projectAssets,
+	config *appConfig,
+	opts *appOptions,
+) {
+	if _, err := os.Stat(filepath.Join(projPath, ".git")); err != nil {
+		output.error("No existing project at %s\n", projPath)
+		os.Exit(1)
+	}
+
+	if !commitMissingFiles(projName, projPath, assets, config, opts) {
+		output.step("Nothing to add, project already has all files\n")
+		return
+	}
+
+	pushChanges(projPath, currentBranch(projPath), config)
+
+	output.step("Success\n")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReinitExisting(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "older")
+	projPath := env.projPath("older")
+
+	writeFile(t, filepath.Join(projPath, "README.md"), "# Edited\n")
+	git(t, projPath, "commit", "-q", "-am", "edit readme")
+	git(t, projPath, "push", "-q")
+
+	mustRun(t, "", "", "--reinit-existing", "--template", "go", "older")
+
+	if creates := env.api.received("POST", "/user/repos"); len(creates) != 1 {
+		t.Errorf("got %d create requests, want only the first run's", len(creates))
+	}
+	if readme := readFile(t, filepath.Join(projPath, "README.md")); readme != "# Edited\n" {
+		t.Errorf("existing README.md overwritten: %q", readme)
+	}
+
+	added := git(t, projPath, "show", "--name-only", "--format=", "HEAD")
+	if !strings.Contains(added, "go.mod") || strings.Contains(added, "README.md") || strings.Contains(added, ".gitignore") {
+		t.Errorf("reinit commit added:\n%s", added)
+	}
+	if status := git(t, projPath, "status", "--porcelain"); status != "" {
+		t.Errorf("uncommitted changes left:\n%s", status)
+	}
+	if isAheadOfOrigin(projPath) {
+		t.Errorf("reinit commit not pushed")
+	}
+}
+
+func TestReinitWithUnpushedCommits(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "wip")
+	projPath := env.projPath("wip")
+	bare := filepath.Join(env.remotes, testUser, "wip.git")
+	pushed := git(t, bare, "rev-parse", "main")
+
+	writeFile(t, filepath.Join(projPath, "wip.txt"), "work in progress\n")
+	git(t, projPath, "add", "wip.txt")
+	git(t, projPath, "commit", "-q", "-m", "wip")
+
+	out := mustRun(t, "", "n\n", "--reinit-existing", "--template", "go", "wip")
+	if strings.Contains(out.stdout, "retrying push") || !strings.Contains(out.stdout, "Add missing files to project") {
+		t.Errorf("reinit not asked for:\n%s", out.stdout)
+	}
+	if got := git(t, bare, "rev-parse", "main"); got != pushed {
+		t.Errorf("unpushed commit pushed although reinit was declined")
+	}
+
+	mustRun(t, "", "", "--reinit-existing", "--template", "go", "wip")
+	if _, err := os.Stat(filepath.Join(projPath, "go.mod")); err != nil {
+		t.Errorf("reinit skipped for a clone with unpushed commits: %v", err)
+	}
+}
+
+func TestReinitNothingMissing(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "complete")
+	head := git(t, env.projPath("complete"), "rev-parse", "HEAD")
+
+	out := mustRun(t, "", "", "--reinit-existing", "complete")
+	if !strings.Contains(out.stdout, "Nothing to add") {
+		t.Errorf("output:\n%s", out.stdout)
+	}
+	if got := git(t, env.projPath("complete"), "rev-parse", "HEAD"); got != head {
+		t.Errorf("reinit committed although nothing was missing")
+	}
+}
+
+func TestCopyMissingFiles(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeFile(t, filepath.Join(src, "a"), "new a")
+	writeFile(t, filepath.Join(src, "dir", "b"), "new b")
+	writeFile(t, filepath.Join(src, ".git", "HEAD"), "ref")
+	writeFile(t, filepath.Join(dst, "a"), "old a")
+
+	copied := copyMissingFiles(src, dst)
+
+	if len(copied) != 1 || copied[0] != filepath.Join("dir", "b") {
+		t.Errorf("copied %q, want only dir/b", copied)
+	}
+	if a := readFile(t, filepath.Join(dst, "a")); a != "old a" {
+		t.Errorf("existing file overwritten: %q", a)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".git")); err == nil {
+		t.Errorf(".git copied")
+	}
+}
+
+func TestCopyMissingSymlinks(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeFile(t, filepath.Join(src, "docs", "guide.md"), "guide")
+	os.Symlink("docs/guide.md", filepath.Join(src, "guide"))
+	os.Symlink("missing", filepath.Join(src, "dangling"))
+	os.Symlink("elsewhere", filepath.Join(dst, "dangling"))
+
+	copied := copyMissingFiles(src, dst)
+
+	if strings.Join(copied, " ") != filepath.Join("docs", "guide.md") + " guide" {
+		t.Errorf("copied %q", copied)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "guide")); err != nil || link != "docs/guide.md" {
+		t.Errorf("guide copied as %q (%v), want a link", link, err)
+	}
+	if link, _ := os.Readlink(filepath.Join(dst, "dangling")); link != "elsewhere" {
+		t.Errorf("existing dangling link replaced by %q", link)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 2
+	defaultMaxRetriesTotal = 5
+	retryBaseDelay = time.Second
+)
+
+var retryConditions = []string{"5xx", "429", "timeout", "connreset"}
+
+// retryBudget caps retries per operation and across the whole run, so a
+// flaky session gives up instead of retrying every step to its own limit.
+// Only failures matching one of retryOn are retried at all. With jitter
+// each delay is drawn from rand between zero and the backoff, so runs
+// failing together do not retry in lockstep.
+type retryBudget struct {
+	perOperation int
+	remaining int
+	retryOn []string
+	jitter bool
+	rand *rand.Rand
+}
+
+// retryableError marks a failure with the retry condition it matches.
+type retryableError struct {
+	condition string
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+func newRetryBudget() retryBudget {
+	return retryBudget{
+		perOperation: defaultMaxRetries,
+		remaining: defaultMaxRetriesTotal,
+		retryOn: retryConditions,
+		rand: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+func parseRetryConditions(s string) []string {
+	conditions := []string{}
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if !slices.Contains(retryConditions, c) {
+			output.error("Unknown retry condition: %s\n", c)
+			os.Exit(1)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions
+}
+
+// classifyNetError maps a transport error to its retry condition, or ""
+// when it is not one we know how to retry.
+func classifyNetError(err error) string {
+	var netErr net.Error
+	if errors.Is(err, os.ErrDeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	// A body cut off mid-transfer is a connection reset as far as retrying
+	// goes.
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || strings.Contains(err.Error(), "connection reset") {
+		return "connreset"
+	}
+	return ""
+}
+
+// classifyGitError does the same for git, which only reports the cause on
+// stderr.
+func classifyGitError(stderr string) string {
+	switch {
+	case strings.Contains(stderr, "timed out"):
+		return "timeout"
+	case strings.Contains(stderr, "Connection reset"):
+		return "connreset"
+	case strings.Contains(stderr, "error: 429"):
+		return "429"
+	case strings.Contains(stderr, "error: 5"):
+		return "5xx"
+	}
+	return ""
+}
+
+// runGitRetryable runs git in dir and wraps failures git reports as
+// transient into retryableError.
+func runGitRetryable(dir string, args ...string) error {
+	stderr := bytes.Buffer{}
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	if condition := classifyGitError(stderr.String()); condition != "" {
+		return &retryableError{condition: condition, err: err}
+	}
+	return err
+}
+
+func retryDelay(attempt int) time.Duration {
+	return retryBaseDelay << (attempt - 1)
+}
+
+func (b *retryBudget) delay(attempt int) time.Duration {
+	d := retryDelay(attempt)
+	if b.jitter {
+		d = time.Duration(b.rand.Int64N(int64(d) + 1))
+	}
+	return d
+}
+
+func (b *retryBudget) shouldRetry(err error) bool {
+	var re *retryableError
+	return errors.As(err, &re) && slices.Contains(b.retryOn, re.condition)
+}
+
+// do runs op until it succeeds, fails with an error not worth retrying or
+// the budget runs out, and returns the last error.
+func (b *retryBudget) do(name string, op func() error) error {
+	err := op()
+
+	for attempt := 1; err != nil && attempt <= b.perOperation && b.remaining > 0; attempt++ {
+		if !b.shouldRetry(err) || runCtx.Err() != nil {
+			break
+		}
+		b.remaining--
+
+		delay := b.delay(attempt)
+		output.warn("%s failed: %v, retrying in %v...\n", name, err, delay.Round(time.Millisecond))
+		select {
+		case <-time.After(delay):
+		case <-runCtx.Done():
+			return context.Cause(runCtx)
+		}
+
+		err = op()
+	}
+
+	return err
+}
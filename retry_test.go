@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noWaitSource makes a jittered budget retry without waiting: rand.Int64N
+// maps 1 to 0 for any bound.
+type noWaitSource struct{}
+
+func (noWaitSource) Uint64() uint64 {
+	return 1
+}
+
+func testRetryBudget(perOperation int, total int) retryBudget {
+	b := newRetryBudget()
+	b.perOperation = perOperation
+	b.remaining = total
+	b.jitter = true
+	b.rand = rand.New(noWaitSource{})
+	return b
+}
+
+func TestRetryBudgetBoundsTotalRetries(t *testing.T) {
+	b := testRetryBudget(3, 4)
+	failing := &retryableError{condition: "5xx", err: errors.New("502 Bad Gateway")}
+
+	calls := []int{}
+	for range 3 {
+		n := 0
+		err := b.do("Op", func() error {
+			n++
+			return failing
+		})
+		if err != failing {
+			t.Errorf("do returned %v, want the last failure", err)
+		}
+		calls = append(calls, n)
+	}
+
+	// 3 retries for the first operation, the 1 left for the second and
+	// none for the third.
+	want := []int{4, 2, 1}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("operation calls = %v, want %v", calls, want)
+			break
+		}
+	}
+	if b.remaining != 0 {
+		t.Errorf("remaining = %d, want 0", b.remaining)
+	}
+}
+
+func TestRetryOnlyMatchingConditions(t *testing.T) {
+	b := testRetryBudget(3, 5)
+	b.retryOn = []string{"timeout"}
+
+	for _, err := range []error{
+		&retryableError{condition: "5xx", err: errors.New("502 Bad Gateway")},
+		errors.New("401 Unauthorized"),
+	} {
+		n := 0
+		b.do("Op", func() error {
+			n++
+			return err
+		})
+		if n != 1 {
+			t.Errorf("%v: called %d times, want 1", err, n)
+		}
+	}
+	if b.remaining != 5 {
+		t.Errorf("remaining = %d, want 5", b.remaining)
+	}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	b := testRetryBudget(3, 5)
+
+	n := 0
+	err := b.do("Op", func() error {
+		n++
+		if n < 3 {
+			return &retryableError{condition: "connreset", err: errors.New("connection reset")}
+		}
+		return nil
+	})
+	if err != nil || n != 3 {
+		t.Errorf("got %v after %d calls, want success after 3", err, n)
+	}
+}
+
+func TestRetryOnExcludesCondition(t *testing.T) {
+	env := newTestEnv(t)
+	env.api.handle("POST /orgs/acme/repos", http.StatusBadGateway, `{"message": "Bad Gateway"}`)
+
+	out := runMain(t, "", "", "--retry-on", "timeout,connreset", "--owner", "acme", "--owner-type", "org", "flaky")
+
+	if out.code != 1 {
+		t.Errorf("exit %d, want 1", out.code)
+	}
+	if n := len(env.api.received("POST", "/orgs/acme/repos")); n != 1 {
+		t.Errorf("got %d create requests, want 1 with 5xx not retried", n)
+	}
+	if strings.Contains(out.stderr, "retrying") {
+		t.Errorf("retried:\n%s", out.stderr)
+	}
+}
+
+func TestParseRetryConditions(t *testing.T) {
+	if got := parseRetryConditions(" 429 ,timeout"); !slices.Equal(got, []string{"429", "timeout"}) {
+		t.Errorf("parsed %q", got)
+	}
+
+	out, code := expectExit(t, func() { parseRetryConditions("5xx,4xx") })
+	if code != 1 || !strings.Contains(out, "Unknown retry condition: 4xx") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestRetryJitterBounds(t *testing.T) {
+	b := newRetryBudget()
+	b.jitter = true
+	b.rand = rand.New(rand.NewPCG(1, 2))
+
+	for attempt := 1; attempt <= 4; attempt++ {
+		max := retryDelay(attempt)
+		draws := map[time.Duration]bool{}
+		for range 50 {
+			d := b.delay(attempt)
+			if d < 0 || d > max {
+				t.Fatalf("attempt %d: delay %v outside of [0, %v]", attempt, d, max)
+			}
+			draws[d] = true
+		}
+		if len(draws) < 10 {
+			t.Errorf("attempt %d: only %d distinct delays, not jittered", attempt, len(draws))
+		}
+	}
+
+	replay := newRetryBudget()
+	replay.jitter = true
+	replay.rand = rand.New(rand.NewPCG(1, 2))
+	b.rand = rand.New(rand.NewPCG(1, 2))
+	for attempt := 1; attempt <= 4; attempt++ {
+		if d, want := b.delay(attempt), replay.delay(attempt); d != want {
+			t.Errorf("attempt %d: %v with the same seed, want %v", attempt, d, want)
+		}
+	}
+
+	b.jitter = false
+	if d := b.delay(3); d != 4 * time.Second {
+		t.Errorf("delay without jitter = %v, want 4s", d)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// scriptEof ends the heredocs emitScript writes files with. Files that
+// contain it, or that a heredoc cannot reproduce byte for byte, are
+// written base64 encoded instead.
+const scriptEof = "CREATE_PROJECT_EOF"
+
+// tokenEnv is the variable the emitted script reads the token of host from,
+// so the token never ends up in the script itself.
+func tokenEnv(host string) string {
+	return strings.ToUpper(host) + "_TOKEN"
+}
+
+func writeScriptFile(script *strings.Builder, rel string, data []byte, mode fs.FileMode) {
+	if dir := filepath.Dir(rel); dir != "." {
+		fmt.Fprintf(script, "mkdir -p %s\n", shellQuote(filepath.ToSlash(dir)))
+	}
+
+	target := shellQuote(filepath.ToSlash(rel))
+	heredoc := len(data) > 0 && bytes.HasSuffix(data, []byte("\n")) &&
+		!bytes.ContainsAny(data, "\x00\r") && !bytes.Contains(data, []byte("\n" + scriptEof + "\n")) &&
+		!bytes.HasPrefix(data, []byte(scriptEof + "\n"))
+
+	switch {
+	case len(data) == 0:
+		fmt.Fprintf(script, ": > %s\n", target)
+	case heredoc:
+		fmt.Fprintf(script, "cat > %s <<'%s'\n%s%s\n", target, scriptEof, data, scriptEof)
+	default:
+		fmt.Fprintf(script, "base64 -d > %s <<'%s'\n%s\n%s\n", target, scriptEof, base64.StdEncoding.EncodeToString(data), scriptEof)
+	}
+
+	if mode & 0111 != 0 {
+		fmt.Fprintf(script, "chmod 755 %s\n", target)
+	}
+}
+
+func writeScriptLink(script *strings.Builder, rel string, link string) {
+	if dir := filepath.Dir(rel); dir != "." {
+		fmt.Fprintf(script, "mkdir -p %s\n", shellQuote(filepath.ToSlash(dir)))
+	}
+	fmt.Fprintf(script, "ln -s %s %s\n", shellQuote(filepath.ToSlash(link)), shellQuote(filepath.ToSlash(rel)))
+}
+
+// emitScript writes a shell script to path, or stdout for -, that creates,
+// clones, scaffolds, commits and pushes the project the way a run would.
+// The files are scaffolded into a scratch dir now and written out by the
+// script, nothing but the templates is fetched and nothing is created.
+func emitScript(projName string, projPath string, config *appConfig, opts *appOptions) {
+	assets := fetchAssets(config, opts)
+	defer assets.cleanup()
+
+	tmp, err := os.MkdirTemp("", "create-project-")
+	iferr("Failed to create temp dir: %v\n", err)
+	defer os.RemoveAll(tmp)
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", "init", "-q", tmp)
+	err = cmd.Run()
+	iferr("Failed to init scratch repository: %v\n", err)
+
+	scaffoldProject(projName, tmp, &assets, config, opts)
+
+	body, err := json.Marshal(createRepoBody(projName, config, opts))
+	iferr("Failed to encode request body: %v\n", err)
+
+	token := tokenEnv(config.host)
+	script := strings.Builder{}
+	fmt.Fprintf(&script, "#!/bin/sh\n# Creates %s/%s, generated by create-project.\nset -eu\n\n", opts.owner, projName)
+	fmt.Fprintf(&script, ": \"${%s:?set %s to the api token}\"\n\n", token, token)
+	fmt.Fprintf(
+		&script,
+		"curl -fsS -X POST -H \"Authorization: token $%s\" -H 'User-Agent: Go' -d %s %s > /dev/null\n",
+		token,
+		shellQuote(string(body)),
+		shellQuote(config.apiUrl() + createRepoEndpoint(opts.owner, opts.ownerType, config)),
+	)
+	fmt.Fprintf(&script, "%s\ncd %s\n\n", cloneCommand(opts.owner, projName, projPath, config), shellQuote(projPath))
+
+	branch := opts.branch
+	if branch == "" && !opts.githubInit {
+		branch = "main"
+	}
+	if branch != "" {
+		fmt.Fprintf(&script, "branch=%s\ngit branch -M \"$branch\"\n\n", shellQuote(branch))
+	} else {
+		script.WriteString("branch=$(git symbolic-ref --short HEAD)\n\n")
+	}
+
+	err = filepath.WalkDir(tmp, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(tmp, p)
+		if err != nil {
+			return err
+		}
+		if d.Type() & fs.ModeSymlink != 0 {
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			writeScriptLink(&script, rel, link)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		writeScriptFile(&script, rel, data, info.Mode())
+		return nil
+	})
+	iferr("Failed to read scaffolded files: %v\n", err)
+
+	if opts.gitHooks {
+		script.WriteString("git config core.hooksPath .githooks\n")
+	}
+
+	commit := "git commit -q -m " + shellQuote(commitMessage("initial commit", opts))
+	if opts.signoff {
+		commit += " -s"
+	}
+	addArgs := "."
+	if len(opts.addPatterns) > 0 {
+		quoted := []string{}
+		for _, p := range opts.addPatterns {
+			quoted = append(quoted, shellQuote(":(glob)" + p))
+		}
+		addArgs = "-- " + strings.Join(quoted, " ")
+	}
+	fmt.Fprintf(&script, "\ngit add %s\n", addArgs)
+	fmt.Fprintf(&script, "if ! git diff --cached --quiet; then\n\t%s\n\tgit push -u origin \"$branch\"\nfi\n", commit)
+
+	path := opts.emitScript
+	if path == "-" {
+		output.result("%s", script.String())
+		return
+	}
+	err = os.WriteFile(path, []byte(script.String()), 0755)
+	iferr("Failed to write script: %v\n", err)
+	output.step("Script written to %s\n", path)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmitScriptSymlinks(t *testing.T) {
+	newTestEnv(t)
+	template := initTemplateRepo(t, map[string]string{
+		"docs/guide.md": "# {{name}}\n",
+		"docs/latest": "->guide.md",
+		"outside": "->/etc/passwd",
+	})
+
+	out := mustRun(t, "", "", "--emit-script", "-", "--template-git", template, "scripted")
+
+	if !strings.Contains(out.stdout, "mkdir -p docs\nln -s guide.md docs/latest\n") {
+		t.Errorf("script does not recreate the symlink:\n%s", out.stdout)
+	}
+	if strings.Contains(out.stdout, "outside") || strings.Contains(out.stdout, "root:") {
+		t.Errorf("script reproduces a symlink out of the template:\n%s", out.stdout)
+	}
+}
+
+func TestEmitScript(t *testing.T) {
+	env := newTestEnv(t)
+	scriptPath := filepath.Join(env.dir, "create.sh")
+
+	mustRun(t, "", "", "--emit-script", scriptPath, "--description", "From a script", "scripted")
+
+	script := readFile(t, scriptPath)
+	for _, want := range []string{
+		": \"${GITHUB_TOKEN:?set GITHUB_TOKEN to the api token}\"\n",
+		"curl -fsS -X POST -H \"Authorization: token $GITHUB_TOKEN\" -H 'User-Agent: Go' -d '{\"description\":\"From a script\",\"name\":\"scripted\"}' " + env.api.URL + "/user/repos > /dev/null\n",
+		"git clone git@github.com:" + testUser + "/scripted.git " + env.projPath("scripted") + "\n",
+		"branch=main\ngit branch -M \"$branch\"\n",
+		"git add .\n",
+		"\tgit commit -q -m 'initial commit'\n\tgit push -u origin \"$branch\"\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script misses %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, testToken) {
+		t.Errorf("token inlined into the script")
+	}
+	if len(env.api.received("POST", "/.*")) > 0 {
+		t.Errorf("emitting the script wrote to the api")
+	}
+	if _, err := os.Stat(env.projPath("scripted")); !os.IsNotExist(err) {
+		t.Errorf("emitting the script created the project")
+	}
+
+	cmd := exec.Command("/bin/sh", scriptPath)
+	cmd.Env = append(os.Environ(), "GITHUB_TOKEN=" + testToken)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 || creates[0].Header.Get("Authorization") != "token " + testToken {
+		t.Errorf("script create requests %v, want one with the token from env", creates)
+	}
+	bare := filepath.Join(env.remotes, testUser, "scripted.git")
+	if got := git(t, bare, "log", "--format=%s", "main"); got != "initial commit" {
+		t.Errorf("script pushed %q to main", got)
+	}
+	if got := git(t, bare, "show", "main:README.md"); !strings.Contains(got, "From a script") {
+		t.Errorf("pushed README.md %q", got)
+	}
+}
+
+func TestEmitScriptUnsupported(t *testing.T) {
+	tests := [][]string{
+		{"--prune-default-labels"},
+		{"--delete-on-empty-push"},
+		{"--notify-command", "true"},
+		{"--notify-webhook", "https://example.com/hook"},
+		{"--edit"},
+		{"--verify-ssh"},
+		{"--owner-fallback"},
+		{"--retry-auth"},
+	}
+
+	for i, args := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			logged, code := expectExit(t, func() { testOptions(append([]string{"--emit-script", "-", "demo"}, args...)...) })
+			if code != 1 || logged != "--emit-script cannot be combined with " + args[0] + "\n" {
+				t.Errorf("%q: exit %d, printed %q", args, code, logged)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// The actions secrets api takes values encrypted as libsodium sealed boxes
+// (crypto_box_seal) to the repository public key, which is what
+// box.SealAnonymous produces.
+
+// sealBox encrypts msg to the X25519 public key pub so that only the
+// holder of the private key can open it.
+func sealBox(msg []byte, pub []byte) ([]byte, error) {
+	return sealBoxWith(msg, pub, rand.Reader)
+}
+
+// sealBoxWith is sealBox with the ephemeral private key read from random.
+func sealBoxWith(msg []byte, pub []byte, random io.Reader) ([]byte, error) {
+	if len(pub) != 32 {
+		return nil, fmt.Errorf("invalid public key of %d bytes", len(pub))
+	}
+	return box.SealAnonymous(nil, msg, (*[32]byte)(pub), random)
+}
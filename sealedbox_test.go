@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// sequence returns n bytes counting up from first.
+func sequence(first byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = first + byte(i)
+	}
+	return b
+}
+
+// openBox is crypto_box_seal_open, for checking what sealBox produces.
+func openBox(t *testing.T, sealed []byte, recipient *ecdh.PrivateKey) []byte {
+	t.Helper()
+
+	msg, ok := box.OpenAnonymous(nil, sealed, (*[32]byte)(recipient.PublicKey().Bytes()), (*[32]byte)(recipient.Bytes()))
+	if !ok {
+		t.Fatalf("sealed box fails authentication")
+	}
+	return msg
+}
+
+func TestSealBox(t *testing.T) {
+	recipient, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []string{"", "s3cr3t", string(make([]byte, 100))} {
+		sealed, err := sealBox([]byte(msg), recipient.PublicKey().Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sealed) != box.AnonymousOverhead + len(msg) {
+			t.Errorf("sealed box of %d bytes is %d bytes", len(msg), len(sealed))
+		}
+		if got := openBox(t, sealed, recipient); string(got) != msg {
+			t.Errorf("opened %q, want %q", got, msg)
+		}
+	}
+
+	if _, err := sealBox([]byte("x"), []byte("short")); err == nil {
+		t.Errorf("sealed to an invalid public key")
+	}
+}
+
+// The expected boxes below come from libsodium 1.0.18. The first is
+// crypto_box_seal with its ephemeral key fixed: the ephemeral public key
+// followed by crypto_box_easy of the message with the nonce
+// crypto_generichash(epk || pk, 24), which crypto_box_seal_open accepts.
+// The second is a crypto_box_seal output as is.
+
+func TestSealBoxKnownAnswer(t *testing.T) {
+	recipient, err := ecdh.X25519().NewPrivateKey(sequence(0x01, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(recipient.PublicKey().Bytes()); got != "07a37cbc142093c8b755dc1b10e86cb426374ad16aa853ed0bdfc0b2b86d1c7c" {
+		t.Fatalf("public key %s", got)
+	}
+
+	sealed, err := sealBoxWith([]byte("s3cr3t value"), recipient.PublicKey().Bytes(), bytes.NewReader(sequence(0x41, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "64b101b1d0be5a8704bd078f9895001fc03e8e9f9522f188dd128d9846d48466" +
+		"e795315ead09a39667ab3a9e2a3d84d68c146b2524490f4a0d0f97d4"
+	if got := hex.EncodeToString(sealed); got != want {
+		t.Errorf("sealed box\n%s\nwant\n%s", got, want)
+	}
+
+	libsodium := unhex(t, "677b23f6328915fe17a45452606c0120916381fe8f4d00fc151ee25bab81db61" +
+		"9c7df859f66dfd1ef5e335403142a4d04a207df29fa19fa836f57d9b6d0f9d3f84a722")
+	if got := string(openBox(t, libsodium, recipient)); got != "sealed by libsodium" {
+		t.Errorf("opened %q", got)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const releaseRepo = "fosseddy/create-project"
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	DownloadUrl string `json:"browser_download_url"`
+}
+
+func releaseAssetName() string {
+	name := fmt.Sprintf("create-project_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+func fetchLatestRelease(config *appConfig) githubRelease {
+	res := githubRequest(http.MethodGet, "/repos/" + releaseRepo + "/releases/latest", nil, config)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		exitWithResponse("Failed to fetch latest release", res)
+	}
+
+	release := githubRelease{}
+	decodeResponse(res, &release)
+	return release
+}
+
+// download fetches url within the same retry budget as the api requests,
+// through the transport meant for downloads.
+func download(url string, config *appConfig) []byte {
+	client := http.Client{Transport: config.downloadTransport()}
+
+	var data []byte
+	err := config.retry.do("Download", func() error {
+		req, err := http.NewRequestWithContext(runCtx, http.MethodGet, url, nil)
+		iferr("Failed to create request: %v\n", err)
+		req.Header.Add("User-Agent", "Go")
+
+		res, err := client.Do(req)
+		if err != nil {
+			return &retryableError{condition: classifyNetError(err), err: err}
+		}
+		defer res.Body.Close()
+
+		switch {
+		case res.StatusCode >= 500:
+			return &retryableError{condition: "5xx", err: fmt.Errorf("server responded with %s", res.Status)}
+		case res.StatusCode == http.StatusTooManyRequests:
+			return &retryableError{condition: "429", err: fmt.Errorf("server responded with %s", res.Status)}
+		case res.StatusCode != http.StatusOK:
+			return fmt.Errorf("server responded with %s", res.Status)
+		}
+
+		data, err = io.ReadAll(res.Body)
+		if err != nil {
+			return &retryableError{condition: classifyNetError(err), err: err}
+		}
+		return nil
+	})
+	iferr("Failed to download release asset: %v\n", err)
+	return data
+}
+
+// findChecksum looks name up in a sha256sum style checksums file.
+func findChecksum(checksums []byte, name string) (string, bool) {
+	s := bufio.NewScanner(strings.NewReader(string(checksums)))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
+
+func verifyChecksum(data []byte, expected string) bool {
+	sum := sha256.Sum256(data)
+	return strings.EqualFold(hex.EncodeToString(sum[:]), expected)
+}
+
+// replaceExecutable writes data next to the running binary and renames it
+// over the original, so an interrupted update never leaves a broken binary.
+func replaceExecutable(data []byte) string {
+	exe, err := os.Executable()
+	iferr("Failed to locate executable: %v\n", err)
+	exe, err = filepath.EvalSymlinks(exe)
+	iferr("Failed to locate executable: %v\n", err)
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".create-project-update-")
+	iferr("Failed to create temp file: %v\n", err)
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	iferr("Failed to write update: %v\n", err)
+	err = tmp.Chmod(0755)
+	iferr("Failed to change file mode: %v\n", err)
+	err = tmp.Close()
+	iferr("Failed to write update: %v\n", err)
+
+	err = os.Rename(tmp.Name(), exe)
+	iferr("Failed to replace executable: %v\n", err)
+
+	return exe
+}
+
+func selfUpdate() {
+	// Releases are public, an empty config makes the requests anonymous.
+	config := appConfig{retry: newRetryBudget()}
+
+	output.step("Checking latest release...\n")
+	release := fetchLatestRelease(&config)
+
+	if release.TagName == version {
+		output.step("Already up to date (%s)\n", version)
+		return
+	}
+
+	data := downloadRelease(release, &config)
+
+	exe := replaceExecutable(data)
+	output.step("Updated %s to %s\n", exe, release.TagName)
+}
+
+// downloadRelease downloads the binary of release for this platform and
+// verifies it against the release's checksums.txt, if it has one.
+func downloadRelease(release githubRelease, config *appConfig) []byte {
+	assetName := releaseAssetName()
+	assetUrl := ""
+	checksumsUrl := ""
+	for _, a := range release.Assets {
+		switch a.Name {
+		case assetName:
+			assetUrl = a.DownloadUrl
+		case "checksums.txt":
+			checksumsUrl = a.DownloadUrl
+		}
+	}
+
+	if assetUrl == "" {
+		output.error("Release %s has no binary for %s/%s\n", release.TagName, runtime.GOOS, runtime.GOARCH)
+		os.Exit(1)
+	}
+
+	output.step("Downloading %s %s...\n", assetName, release.TagName)
+	data := download(assetUrl, config)
+
+	if checksumsUrl != "" {
+		expected, ok := findChecksum(download(checksumsUrl, config), assetName)
+		if !ok {
+			output.error("No checksum for %s in checksums.txt\n", assetName)
+			os.Exit(1)
+		}
+		if !verifyChecksum(data, expected) {
+			output.error("Checksum mismatch for %s\n", assetName)
+			os.Exit(1)
+		}
+	} else {
+		output.warn("release has no checksums.txt, skipping verification\n")
+	}
+
+	return data
+}
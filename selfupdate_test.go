@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// testRelease is a release of binary on api, with checksums.txt holding
+// checksums unless it is "".
+func testRelease(api *mockApi, binary string, checksums string) githubRelease {
+	api.handle("GET /download/" + releaseAssetName(), http.StatusOK, binary)
+	release := githubRelease{TagName: "v9.9.9"}
+	release.Assets = append(release.Assets, releaseAsset{releaseAssetName(), api.URL + "/download/" + releaseAssetName()})
+
+	if checksums != "" {
+		api.handle("GET /download/checksums.txt", http.StatusOK, checksums)
+		release.Assets = append(release.Assets, releaseAsset{"checksums.txt", api.URL + "/download/checksums.txt"})
+	}
+	return release
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadRelease(t *testing.T) {
+	api := newMockApi(t)
+	checksums := fmt.Sprintf("%s  other\n%s *%s\n", sha256Hex("other"), sha256Hex("new binary"), releaseAssetName())
+	release := testRelease(api, "new binary", checksums)
+	captureOutput(t)
+
+	if data := downloadRelease(release, testConfig(api)); string(data) != "new binary" {
+		t.Errorf("downloaded %q", data)
+	}
+	if got := api.received("GET", "/download/.*"); len(got) != 2 || got[0].Header.Get("User-Agent") != "Go" {
+		t.Errorf("download requests = %v", got)
+	}
+}
+
+func TestDownloadReleaseChecksumMismatch(t *testing.T) {
+	api := newMockApi(t)
+	release := testRelease(api, "tampered binary", sha256Hex("new binary") + "  " + releaseAssetName() + "\n")
+
+	out, code := expectExit(t, func() { downloadRelease(release, testConfig(api)) })
+	if code != 1 || !strings.Contains(out, "Checksum mismatch") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestDownloadReleaseChecksumMissing(t *testing.T) {
+	api := newMockApi(t)
+	release := testRelease(api, "new binary", sha256Hex("other") + "  other\n")
+
+	out, code := expectExit(t, func() { downloadRelease(release, testConfig(api)) })
+	if code != 1 || !strings.Contains(out, "No checksum for " + releaseAssetName()) {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+func TestDownloadRetries(t *testing.T) {
+	api := newMockApi(t)
+	failures := 1
+	api.handleFunc("GET /flaky", func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, "finally")
+	})
+
+	config := testConfig(api)
+	config.retry = testRetryBudget(2, 2)
+	captureOutput(t)
+	if data := download(api.URL + "/flaky", config); string(data) != "finally" {
+		t.Errorf("downloaded %q", data)
+	}
+	if n := len(api.received("GET", "/flaky")); n != 2 {
+		t.Errorf("got %d requests, want 2", n)
+	}
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const goMainTemplate = `package main
+
+import "fmt"
+
+func greeting() string {
+	return "Hello, World!"
+}
+
+func main() {
+	fmt.Println(greeting())
+}
+`
+
+const goTestTemplate = `package main
+
+import "testing"
+
+func TestGreeting(t *testing.T) {
+	if got := greeting(); got != "Hello, World!" {
+		t.Errorf("greeting() = %q, want %q", got, "Hello, World!")
+	}
+}
+`
+
+// dockerTemplates holds the Dockerfile and .dockerignore for each language
+// template the docker template can be combined with.
+var dockerTemplates = map[string][2]string{
+	"go": {
+		`FROM golang:1 AS build
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -o /out/{{name}} .
+
+FROM gcr.io/distroless/static-debian12
+COPY --from=build /out/{{name}} /{{name}}
+ENTRYPOINT ["/{{name}}"]
+`,
+		`.git
+.githooks
+Dockerfile
+.dockerignore
+`,
+	},
+}
+
+// makefileTemplates holds the Makefile --makefile writes for each language
+// template.
+var makefileTemplates = map[string]string{
+	"go": `BIN := {{name}}
+
+.PHONY: build test lint clean
+
+build:
+	go build -o $(BIN) .
+
+test:
+	go test ./...
+
+lint:
+	test -z "$$(gofmt -l .)"
+	go vet ./...
+
+clean:
+	rm -f $(BIN)
+`,
+}
+
+// templateFiles lists the files each template writes, so composing
+// templates can warn when a later one overwrites an earlier one's file.
+var templateFiles = map[string][]string{
+	"go": {"go.mod", "main.go", "main_test.go"},
+	"docker": {"Dockerfile", ".dockerignore"},
+}
+
+// templateFormatters holds the formatter command run over a template's
+// files by --format. Templates without one are left as rendered.
+var templateFormatters = map[string][]string{
+	"go": {"gofmt", "-w", "."},
+}
+
+// primaryLanguage returns the first language template of templates, the one
+// templates like docker tailor their files to.
+func primaryLanguage(templates []string) string {
+	for _, t := range templates {
+		if _, ok := dockerTemplates[t]; ok {
+			return t
+		}
+	}
+	return ""
+}
+
+// parseTemplates parses a comma separated template list. none is only
+// allowed alone and yields an empty list.
+func parseTemplates(s string) []string {
+	templates := []string{}
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := templateFiles[name]; !ok && name != "none" {
+			output.error("Unknown template: %s\n", name)
+			os.Exit(1)
+		}
+		if slices.Contains(templates, name) {
+			continue
+		}
+		templates = append(templates, name)
+	}
+
+	if slices.Contains(templates, "none") {
+		if len(templates) > 1 {
+			output.error("Template none cannot be combined with other templates\n")
+			os.Exit(1)
+		}
+		return []string{}
+	}
+
+	return templates
+}
+
+// resolveTemplate falls back to the configured default templates and dirs.
+// An explicit --template none wins over the default and scaffolds nothing.
+func resolveTemplate(opts *appOptions, config *appConfig) {
+	if opts.templates == nil && !opts.noDefaultFiles {
+		opts.templates = config.defaultTemplates
+	}
+	if opts.dirs == nil && !opts.noDefaultFiles {
+		opts.dirs = config.defaultDirs
+	}
+
+	if opts.modulePath != "" && !slices.Contains(opts.templates, "go") {
+		output.error("--module-path requires --template go\n")
+		os.Exit(1)
+	}
+
+	if opts.withTests && !slices.Contains(opts.templates, "go") {
+		output.error("--with-tests requires --template go\n")
+		os.Exit(1)
+	}
+
+	if opts.makefile && primaryLanguage(opts.templates) == "" {
+		output.error("--makefile requires a language template (go)\n")
+		os.Exit(1)
+	}
+
+	if slices.Contains(opts.templates, "docker") && primaryLanguage(opts.templates) == "" {
+		output.error("Template docker requires a language template (go)\n")
+		os.Exit(1)
+	}
+}
+
+func isValidModulePath(p string) bool {
+	elems := strings.Split(p, "/")
+	if !strings.Contains(elems[0], ".") {
+		return false
+	}
+
+	for _, elem := range elems {
+		if elem == "" || strings.HasPrefix(elem, ".") || strings.HasSuffix(elem, ".") {
+			return false
+		}
+		for _, r := range elem {
+			isAlnum := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+			if !isAlnum && !strings.ContainsRune("-._~", r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func applyGoTemplate(projName string, projPath string, config *appConfig, opts *appOptions) {
+	modulePath := opts.modulePath
+	if modulePath == "" {
+		modulePath = fmt.Sprintf("%s/%s/%s", config.gitHost(), opts.owner, projName)
+	}
+
+	cmd := exec.CommandContext(runCtx, "go", "mod", "init", modulePath)
+	cmd.Dir = projPath
+	err := cmd.Run()
+	iferr("Failed to initialize go module: %v\n", err)
+
+	f := createFile(filepath.Join(projPath, "main.go"))
+	f.WriteString(goMainTemplate)
+	f.Close()
+
+	if opts.withTests {
+		f = createFile(filepath.Join(projPath, "main_test.go"))
+		f.WriteString(goTestTemplate)
+		f.Close()
+	}
+}
+
+func applyDockerTemplate(projName string, projPath string, opts *appOptions) {
+	docker := dockerTemplates[primaryLanguage(opts.templates)]
+	vars := templateVars(projName, opts.owner)
+
+	f := createFile(filepath.Join(projPath, "Dockerfile"))
+	f.Write(substituteVars([]byte(docker[0]), vars))
+	f.Close()
+
+	f = createFile(filepath.Join(projPath, ".dockerignore"))
+	f.Write(substituteVars([]byte(docker[1]), vars))
+	f.Close()
+}
+
+func applyTemplate(name string, projName string, projPath string, config *appConfig, opts *appOptions) {
+	for _, f := range templateFiles[name] {
+		if _, err := os.Stat(filepath.Join(projPath, f)); err == nil {
+			output.warn("%s template overwrites %s\n", name, f)
+			err = os.Remove(filepath.Join(projPath, f))
+			iferr("Failed to remove file: %v\n", err)
+		}
+	}
+
+	switch name {
+	case "go":
+		applyGoTemplate(projName, projPath, config, opts)
+	case "docker":
+		applyDockerTemplate(projName, projPath, opts)
+	}
+}
+
+func createMakefile(projName string, projPath string, opts *appOptions) {
+	makefile := makefileTemplates[primaryLanguage(opts.templates)]
+
+	f := createFile(filepath.Join(projPath, "Makefile"))
+	f.Write(substituteVars([]byte(makefile), templateVars(projName, opts.owner)))
+	f.Close()
+}
+
+func formatTemplate(name string, projPath string) {
+	formatter, ok := templateFormatters[name]
+	if !ok {
+		return
+	}
+
+	if _, err := exec.LookPath(formatter[0]); err != nil {
+		output.warn("%s not found, skipping formatting of %s template\n", formatter[0], name)
+		return
+	}
+
+	output.step("Formatting %s template with %s...\n", name, formatter[0])
+	cmd := exec.CommandContext(runCtx, formatter[0], formatter[1:]...)
+	cmd.Dir = projPath
+	cmd.Stderr = output.raw()
+	err := cmd.Run()
+	iferr("Failed to format template files: %v\n", err)
+}
+
+func templateVars(projName string, owner string) map[string]string {
+	return map[string]string{
+		"name": projName,
+		"title": buildTitle(projName),
+		"owner": owner,
+	}
+}
+
+func substituteVars(data []byte, vars map[string]string) []byte {
+	for k, v := range vars {
+		data = bytes.ReplaceAll(data, []byte("{{" + k + "}}"), []byte(v))
+	}
+	return data
+}
+
+// readTemplateGit shallow clones url into a temp dir and drops its history,
+// leaving just the template files. The caller removes the dir, nothing is
+// left behind when it fails.
+func readTemplateGit(url string) (string, error) {
+	tmp, err := os.MkdirTemp("", "create-project-template-")
+	if err != nil {
+		return "", fmt.Errorf("Failed to create temp dir: %v", err)
+	}
+
+	cmd := exec.CommandContext(runCtx, "/bin/git", "clone", "--depth", "1", url, tmp)
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("Failed to clone template repository %s: %v", url, err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("Failed to remove template history: %v", err)
+	}
+
+	return tmp, nil
+}
+
+// isArchiveUrl reports whether url names a template archive rather than a
+// git repository.
+func isArchiveUrl(url string) bool {
+	isHttp := strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")
+	return isHttp && (strings.HasSuffix(url, ".tar.gz") || strings.HasSuffix(url, ".tgz") || strings.HasSuffix(url, ".zip"))
+}
+
+// readTemplate fetches the template archive or git repository at url into
+// a temp dir the caller removes.
+func readTemplate(url string, config *appConfig) (string, error) {
+	if isArchiveUrl(url) {
+		return readTemplateArchive(url, config)
+	}
+	return readTemplateGit(url)
+}
+
+// fetchTemplateChain fetches the template repository at url along with the
+// templates it extends, each copied over its base so it can override
+// single files. The returned manifest holds the variables of the whole
+// chain, a template's own definition winning over its base's.
+func fetchTemplateChain(url string, config *appConfig) (string, templateManifest) {
+	dir, manifest, err := readTemplateChain(url, nil, config)
+	if err != nil {
+		output.error("%v\n", err)
+		os.Exit(1)
+	}
+	return dir, manifest
+}
+
+// readTemplateChain is fetchTemplateChain returning the error instead. It
+// removes what it fetched when any template of the chain fails.
+func readTemplateChain(url string, seen []string, config *appConfig) (string, templateManifest, error) {
+	if slices.Contains(seen, url) {
+		return "", templateManifest{}, fmt.Errorf("Template %s extends itself", url)
+	}
+	seen = append(seen, url)
+
+	dir, err := readTemplate(url, config)
+	if err != nil {
+		return "", templateManifest{}, err
+	}
+	manifest, err := readTemplateManifest(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", manifest, err
+	}
+	if manifest.Extends == "" {
+		return dir, manifest, nil
+	}
+
+	output.step("Fetching base template %s...\n", manifest.Extends)
+	baseDir, base, err := readTemplateChain(manifest.Extends, seen, config)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", manifest, err
+	}
+	copyTemplateDir(dir, baseDir, nil, nil)
+	mergeTemplateIgnore(dir, baseDir)
+	os.RemoveAll(dir)
+
+	merged := templateManifest{Description: manifest.Description}
+	if merged.Description == "" {
+		merged.Description = base.Description
+	}
+	for _, v := range base.Variables {
+		if !slices.ContainsFunc(manifest.Variables, func(o templatePrompt) bool { return o.Name == v.Name }) {
+			merged.Variables = append(merged.Variables, v)
+		}
+	}
+	merged.Variables = append(merged.Variables, manifest.Variables...)
+
+	return baseDir, merged, nil
+}
+
+// mergeTemplateIgnore appends the .templateignore of dir to the one of its
+// base template in baseDir, so the base's patterns keep applying and dir's
+// can override them.
+func mergeTemplateIgnore(dir string, baseDir string) {
+	data, err := os.ReadFile(filepath.Join(dir, templateIgnoreName))
+	if os.IsNotExist(err) {
+		return
+	}
+	iferr("Failed to read template ignore file: %v\n", err)
+
+	f, err := os.OpenFile(filepath.Join(baseDir, templateIgnoreName), os.O_CREATE | os.O_WRONLY | os.O_APPEND, 0644)
+	iferr("Failed to open template ignore file: %v\n", err)
+	defer f.Close()
+
+	_, err = f.Write(append([]byte("\n"), stripBom(string(data))...))
+	iferr("Failed to write template ignore file: %v\n", err)
+}
+
+// linkInside reports whether the symlink at p is relative and resolves to
+// a path inside root, which has to be resolved itself. A link that does not
+// resolve at all is not inside.
+func linkInside(p string, root string) bool {
+	link, err := os.Readlink(p)
+	if err != nil || filepath.IsAbs(link) {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, resolved)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// copyTemplateDir copies every file of src not matched by ignore into dst,
+// replacing {{var}} placeholders in text files. Files already in dst are
+// overwritten. Symlinks are recreated as they are if they stay inside src
+// and skipped otherwise, so a template cannot pull in files from the host,
+// and .git is skipped.
+func copyTemplateDir(src string, dst string, vars map[string]string, ignore templateIgnore) {
+	root, err := filepath.EvalSymlinks(src)
+	iferr("Failed to resolve template dir: %v\n", err)
+
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		// Whatever a template has in .git would end up in the git dir of
+		// the project, hooks git runs included.
+		if strings.EqualFold(d.Name(), ".git") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel != "." && ignore.ignores(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if rel == templateManifestName || rel == templateIgnoreName {
+			return nil
+		}
+
+		// Replace what a base template left at target instead of writing
+		// through a link of it.
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if d.Type() & fs.ModeSymlink != 0 {
+			if !linkInside(p, root) {
+				output.warn("skipping symlink %s, it points outside of the template\n", rel)
+				return nil
+			}
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(data, []byte{0}) {
+			data = fileStyle.apply(substituteVars(data, vars))
+		}
+
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+	iferr("Failed to copy template files: %v\n", err)
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoTemplateModulePath(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--template", "go", "--owner", "someone", "tool"}, "module github.com/someone/tool"},
+		{[]string{"--template", "go", "--module-path", "example.com/x/tool", "tool"}, "module example.com/x/tool"},
+	}
+
+	for _, tt := range tests {
+		projPath := t.TempDir()
+		opts := testOptions(tt.args...)
+		applyGoTemplate(opts.projName, projPath, testConfig(nil), opts)
+
+		gomod := readFile(t, filepath.Join(projPath, "go.mod"))
+		if line, _, _ := strings.Cut(gomod, "\n"); line != tt.want {
+			t.Errorf("%q: go.mod starts with %q, want %q", tt.args, line, tt.want)
+		}
+	}
+}
+
+func TestGoTemplateWithTests(t *testing.T) {
+	projPath := t.TempDir()
+	opts := testOptions("--template", "go", "--with-tests", "--owner", "someone", "tool")
+	applyGoTemplate(opts.projName, projPath, testConfig(nil), opts)
+
+	test := readFile(t, filepath.Join(projPath, "main_test.go"))
+	if !strings.Contains(test, "func Test") {
+		t.Errorf("main_test.go has no test function:\n%s", test)
+	}
+
+	cmd := exec.Command("go", "test", "./...")
+	cmd.Dir = projPath
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go test in the generated project failed: %v\n%s", err, out)
+	}
+
+	projPath = t.TempDir()
+	opts = testOptions("--template", "go", "--owner", "someone", "tool")
+	applyGoTemplate(opts.projName, projPath, testConfig(nil), opts)
+	if _, err := os.Stat(filepath.Join(projPath, "main_test.go")); err == nil {
+		t.Errorf("main_test.go generated without --with-tests")
+	}
+}
+
+func TestTemplateNoneOverridesDefault(t *testing.T) {
+	env := newTestEnv(t, "default_template = go")
+	mustRun(t, "", "", "--template", "none", "plain")
+
+	if _, err := os.Stat(filepath.Join(env.projPath("plain"), "go.mod")); err == nil {
+		t.Errorf("default go template applied despite --template none")
+	}
+
+	mustRun(t, "", "", "defaulted")
+	if _, err := os.Stat(filepath.Join(env.projPath("defaulted"), "go.mod")); err != nil {
+		t.Errorf("default go template not applied without --template: %v", err)
+	}
+}
+
+func TestTemplateNoneAlone(t *testing.T) {
+	out, code := expectExit(t, func() { parseTemplates("none,go") })
+	if code != 1 || !strings.Contains(out, "cannot be combined") {
+		t.Errorf("exit %d, output %q", code, out)
+	}
+}
+
+// initTemplateRepo creates a git repository to use as --template-git from
+// files, where a value starting with "->" is a symlink to the rest.
+func initTemplateRepo(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if link, ok := strings.CutPrefix(content, "->"); ok {
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(link, p); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		writeFile(t, p, content)
+	}
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "template")
+	return dir
+}
+
+func TestTemplateGit(t *testing.T) {
+	env := newTestEnv(t)
+	secret := filepath.Join(env.dir, "secret")
+	writeFile(t, secret, "host secret\n")
+
+	template := initTemplateRepo(t, map[string]string{
+		"main.go": "// {{title}} by {{owner}}\npackage main\n",
+		"docs/guide.md": "# {{name}}\n",
+		"docs/main.go": "->../main.go",
+		"latest": "->docs",
+		"absolute": "->" + secret,
+		"relative": "->../" + filepath.Base(env.dir) + "/secret",
+	})
+
+	out := mustRun(t, "", "", "--template-git", template, "my-app")
+	projPath := env.projPath("my-app")
+
+	if got := readFile(t, filepath.Join(projPath, "main.go")); got != "// My App by " + testUser + "\npackage main\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if got := readFile(t, filepath.Join(projPath, "docs", "guide.md")); got != "# my-app\n" {
+		t.Errorf("docs/guide.md = %q", got)
+	}
+	if got := git(t, projPath, "log", "--format=%s"); got != "initial commit" {
+		t.Errorf("template history leaked into project: %q", got)
+	}
+
+	for name, want := range map[string]string{"docs/main.go": "../main.go", "latest": "docs"} {
+		if link, err := os.Readlink(filepath.Join(projPath, name)); err != nil || link != want {
+			t.Errorf("%s links to %q (%v), want %q", name, link, err, want)
+		}
+	}
+	for _, name := range []string{"absolute", "relative"} {
+		if _, err := os.Lstat(filepath.Join(projPath, name)); err == nil {
+			t.Errorf("symlink %s out of the template copied", name)
+		}
+		if !strings.Contains(out.stderr, "skipping symlink " + name) {
+			t.Errorf("no warning about symlink %s:\n%s", name, out.stderr)
+		}
+	}
+}
+
+func TestCopyTemplateDirSkipsGit(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeFile(t, filepath.Join(src, "README.md"), "# {{name}}\n")
+	writeFile(t, filepath.Join(src, ".git", "hooks", "pre-commit"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(src, "sub", ".git"), "gitdir: /elsewhere\n")
+
+	copyTemplateDir(src, dst, map[string]string{"name": "demo"}, templateIgnore{})
+
+	if got := readFile(t, filepath.Join(dst, "README.md")); got != "# demo\n" {
+		t.Errorf("README.md = %q", got)
+	}
+	for _, name := range []string{".git", filepath.Join("sub", ".git")} {
+		if _, err := os.Lstat(filepath.Join(dst, name)); err == nil {
+			t.Errorf("%s copied", name)
+		}
+	}
+}
+
+func TestValidateTemplateSymlinks(t *testing.T) {
+	newTestEnv(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "README.md"), "# {{name}}\n")
+	os.Symlink("README.md", filepath.Join(dir, "inside"))
+	os.Symlink("/etc/passwd", filepath.Join(dir, "outside"))
+
+	out := runMain(t, "", "", "--validate-template", dir)
+	if out.code != 1 || !strings.Contains(out.stdout + out.stderr, "outside: symlink points outside of the template") {
+		t.Errorf("exit %d, output:\n%s%s", out.code, out.stdout, out.stderr)
+	}
+	if strings.Contains(out.stdout + out.stderr, "inside:") {
+		t.Errorf("symlink inside of the template reported")
+	}
+}
+
+func TestCombinedTemplates(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--template", "go,docker", "combined")
+	projPath := env.projPath("combined")
+
+	for _, f := range []string{"go.mod", "main.go", "Dockerfile", ".dockerignore"} {
+		if _, err := os.Stat(filepath.Join(projPath, f)); err != nil {
+			t.Errorf("%s missing: %v", f, err)
+		}
+	}
+	if got := git(t, projPath, "ls-files", "Dockerfile", "go.mod"); got != "Dockerfile\ngo.mod" {
+		t.Errorf("template files not committed: %q", got)
+	}
+}
+
+func TestTemplateConflictWarning(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile(t, filepath.Join(projPath, "main.go"), "package old\n")
+	logged := captureOutput(t)
+
+	opts := testOptions("--template", "go", "--owner", "someone", "tool")
+	applyTemplate("go", "tool", projPath, testConfig(nil), opts)
+
+	if !strings.Contains(logged.String(), "Warning: go template overwrites main.go") {
+		t.Errorf("no conflict warning:\n%s", logged)
+	}
+	if got := readFile(t, filepath.Join(projPath, "main.go")); !strings.HasPrefix(got, "package main") {
+		t.Errorf("main.go = %q, want the go template's", got)
+	}
+}
+
+func TestDockerTemplateBaseImage(t *testing.T) {
+	projPath := t.TempDir()
+	opts := testOptions("--template", "go,docker", "--owner", "someone", "svc")
+	applyDockerTemplate("svc", projPath, opts)
+
+	dockerfile := readFile(t, filepath.Join(projPath, "Dockerfile"))
+	if !strings.HasPrefix(dockerfile, "FROM golang:") {
+		t.Errorf("Dockerfile does not build on the go image:\n%s", dockerfile)
+	}
+	if !strings.Contains(dockerfile, "ENTRYPOINT [\"/svc\"]") {
+		t.Errorf("Dockerfile does not run the project binary:\n%s", dockerfile)
+	}
+	if ignore := readFile(t, filepath.Join(projPath, ".dockerignore")); !strings.Contains(ignore, ".git\n") {
+		t.Errorf(".dockerignore = %q", ignore)
+	}
+}
+
+func TestDockerTemplateRequiresLanguage(t *testing.T) {
+	newTestEnv(t)
+	out := runMain(t, "", "", "--template", "docker", "svc")
+	if out.code != 1 || !strings.Contains(out.stderr, "Template docker requires a language template") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	bin := t.TempDir()
+	log := filepath.Join(bin, "gofmt.log")
+	writeFile(t, filepath.Join(bin, "gofmt"), "#!/bin/sh\necho \"$PWD $@\" >> " + shellQuote(log) + "\n")
+	if err := os.Chmod(filepath.Join(bin, "gofmt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	projPath := t.TempDir()
+	captureOutput(t)
+	formatTemplate("go", projPath)
+	formatTemplate("docker", projPath)
+
+	if got := readFile(t, log); got != projPath + " -w .\n" {
+		t.Errorf("gofmt ran as %q", got)
+	}
+}
+
+func TestFormatTemplateWithoutFormatter(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	logged := captureOutput(t)
+
+	formatTemplate("go", t.TempDir())
+	if !strings.Contains(logged.String(), "Warning: gofmt not found, skipping formatting of go template") {
+		t.Errorf("no warning:\n%s", logged)
+	}
+}
+
+func TestTemplateExtends(t *testing.T) {
+	env := newTestEnv(t)
+	base := initTemplateRepo(t, map[string]string{
+		templateManifestName: `{"variables": [{"name": "port", "default": "8080"}, {"name": "db", "default": "sqlite"}]}`,
+		"README.md": "# base readme\n",
+		"config.env": "PORT={{port}}\nDB={{db}}\n",
+	})
+	child := initTemplateRepo(t, map[string]string{
+		templateManifestName: `{"extends": "` + base + `", "variables": [{"name": "db", "default": "postgres"}]}`,
+		"README.md": "# {{title}}, from the child\n",
+	})
+
+	out := mustRun(t, "", "y\n\n\n", "--template-git", child, "derived")
+	projPath := env.projPath("derived")
+
+	if got := readFile(t, filepath.Join(projPath, "README.md")); got != "# Derived, from the child\n" {
+		t.Errorf("README.md = %q, want the child's", got)
+	}
+	if got := readFile(t, filepath.Join(projPath, "config.env")); got != "PORT=8080\nDB=postgres\n" {
+		t.Errorf("config.env = %q, want the base's with the child's default", got)
+	}
+	if !strings.Contains(out.stdout, "Fetching base template " + base) {
+		t.Errorf("base not fetched:\n%s", out.stdout)
+	}
+}
+
+func TestTemplateExtendsItself(t *testing.T) {
+	env := newTestEnv(t)
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	writeFile(t, filepath.Join(dir, templateManifestName), `{"extends": "` + dir + `"}`)
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "template")
+
+	out := runMain(t, "", "y\n", "--template-git", dir, "looped")
+	if out.code != 1 || !strings.Contains(out.stderr, "Template " + dir + " extends itself") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+	if got := len(env.api.received("POST", ".*")); got != 0 {
+		t.Errorf("repository created for a looping template")
+	}
+}
+
+func TestTemplateDescription(t *testing.T) {
+	env := newTestEnv(t)
+	template := initTemplateRepo(t, map[string]string{
+		templateManifestName: `{"description": "A {{lang}} {{kind}} named {{title}} :rocket:", "variables": [{"name": "kind", "default": "service"}]}`,
+		"notes.txt": "{{name}}\n",
+	})
+
+	mustRun(t, "", "y\n\n", "--template", "go", "--owner", testUser, "--template-git", template, "my-api")
+
+	want := "A go service named My Api \U0001F680"
+	creates := env.api.received("POST", "/user/repos")
+	if len(creates) != 1 || creates[0].json(t)["description"] != want {
+		t.Fatalf("create requests %v, want description %q", creates, want)
+	}
+	if readme := readFile(t, filepath.Join(env.projPath("my-api"), "README.md")); !strings.Contains(readme, want) {
+		t.Errorf("README.md has no rendered description:\n%s", readme)
+	}
+
+	mustRun(t, "", "y\n\n", "--template-git", template, "--description", "Given", "given")
+	creates = env.api.received("POST", "/user/repos")
+	if got := creates[len(creates) - 1].json(t)["description"]; got != "Given" {
+		t.Errorf("--description overridden by the template: %q", got)
+	}
+}
+
+func TestGoMakefile(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--template", "go", "--with-tests", "--makefile", "--owner", testUser, "made")
+	projPath := env.projPath("made")
+
+	makefile := readFile(t, filepath.Join(projPath, "Makefile"))
+	if !strings.Contains(makefile, "BIN := made\n") || !strings.Contains(makefile, "\ntest:\n\tgo test ./...\n") {
+		t.Errorf("Makefile has no go test target:\n%s", makefile)
+	}
+	for _, target := range []string{"build", "test", "lint", "clean"} {
+		if !strings.Contains(makefile, "\n" + target + ":\n") {
+			t.Errorf("Makefile has no %s target", target)
+		}
+	}
+
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not installed")
+	}
+	cmd := exec.Command("make", "-n", "test")
+	cmd.Dir = projPath
+	if out, err := cmd.CombinedOutput(); err != nil || string(out) != "go test ./...\n" {
+		t.Errorf("make -n test: %v\n%s", err, out)
+	}
+}
+
+func TestMakefileRequiresLanguage(t *testing.T) {
+	newTestEnv(t)
+	out := runMain(t, "", "", "--makefile", "made")
+	if out.code != 1 || !strings.Contains(out.stderr, "--makefile requires a language template (go)") {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+}
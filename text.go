@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+)
+
+const utf8Bom = "\uFEFF"
+
+// textStyle is the newline style and charset generated files are written
+// in, set from the newline and charset config fields.
+type textStyle struct {
+	crlf bool
+	bom bool
+}
+
+// fileStyle is the textStyle of the run, createFile and the template copy
+// write with it.
+var fileStyle = textStyle{}
+
+func parseNewline(v string) bool {
+	if v != "lf" && v != "crlf" {
+		output.error("Invalid newline, expected lf or crlf: %s\n", v)
+		os.Exit(1)
+	}
+	return v == "crlf"
+}
+
+func parseCharset(v string) bool {
+	if v != "utf-8" && v != "utf-8-bom" {
+		output.error("Invalid charset, expected utf-8 or utf-8-bom: %s\n", v)
+		os.Exit(1)
+	}
+	return v == "utf-8-bom"
+}
+
+// apply rewrites the whole file data in style s. Scripts starting with #!
+// are left as they are, a shell would not run them with CRLF or a BOM.
+func (s textStyle) apply(data []byte) []byte {
+	data = bytes.TrimPrefix(data, []byte(utf8Bom))
+	if bytes.HasPrefix(data, []byte("#!")) {
+		return data
+	}
+
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if s.crlf {
+		data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+	}
+	if s.bom {
+		data = append([]byte(utf8Bom), data...)
+	}
+	return data
+}
+
+// textFile writes a generated file in fileStyle. The first write decides
+// whether it is a script left alone.
+type textFile struct {
+	f *os.File
+	started bool
+	script bool
+}
+
+func (t *textFile) Write(p []byte) (int, error) {
+	if !t.started {
+		t.started = true
+		t.script = bytes.HasPrefix(p, []byte("#!"))
+		if fileStyle.bom && !t.script {
+			if _, err := t.f.WriteString(utf8Bom); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	data := p
+	if fileStyle.crlf && !t.script {
+		data = bytes.ReplaceAll(bytes.ReplaceAll(p, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+	}
+	if _, err := t.f.Write(data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (t *textFile) WriteString(s string) (int, error) {
+	return t.Write([]byte(s))
+}
+
+func (t *textFile) Chmod(mode os.FileMode) error {
+	return t.f.Chmod(mode)
+}
+
+func (t *textFile) Close() error {
+	return t.f.Close()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTextStyleApply(t *testing.T) {
+	tests := []struct {
+		style textStyle
+		in string
+		want string
+	}{
+		{textStyle{}, "a\r\nb\n", "a\nb\n"},
+		{textStyle{crlf: true}, "a\nb\r\n", "a\r\nb\r\n"},
+		{textStyle{bom: true}, "\ufeffa\n", "\ufeffa\n"},
+		{textStyle{crlf: true, bom: true}, "a\n", "\ufeffa\r\n"},
+		{textStyle{crlf: true, bom: true}, "#!/bin/sh\necho\n", "#!/bin/sh\necho\n"},
+	}
+
+	for _, tt := range tests {
+		if got := string(tt.style.apply([]byte(tt.in))); got != tt.want {
+			t.Errorf("%+v.apply(%q) = %q, want %q", tt.style, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewlineStyle(t *testing.T) {
+	env := newTestEnv(t, "newline = crlf", "charset = utf-8-bom")
+	template := initTemplateRepo(t, map[string]string{
+		"docs/guide.md": "# Guide\nline\n",
+		"run.sh": "#!/bin/sh\necho run\n",
+	})
+
+	mustRun(t, "", "", "--description", "Styled", "--template-git", template, "styled")
+	projPath := env.projPath("styled")
+
+	for _, name := range []string{"README.md", "docs/guide.md"} {
+		got := readFile(t, filepath.Join(projPath, name))
+		if !strings.HasPrefix(got, "\ufeff") || !strings.Contains(got, "\r\n") || strings.Contains(strings.ReplaceAll(got, "\r\n", ""), "\n") {
+			t.Errorf("%s not written with a BOM and CRLF: %q", name, got)
+		}
+	}
+	if got := readFile(t, filepath.Join(projPath, "run.sh")); got != "#!/bin/sh\necho run\n" {
+		t.Errorf("script rewritten: %q", got)
+	}
+}
+
+func TestInvalidTextStyle(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"newline = cr", "Invalid newline, expected lf or crlf: cr\n"},
+		{"charset = latin1", "Invalid charset, expected utf-8 or utf-8-bom: latin1\n"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			newTestEnv(t, tt.line)
+			out := runMain(t, "", "", "demo")
+			if out.code != 1 || !strings.HasSuffix(out.stderr, tt.want) {
+				t.Errorf("%s: exit %d, stderr %q", tt.line, out.code, out.stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+)
+
+// traceTransport prints every request and response it carries to out for
+// --trace. The Authorization header is redacted. With headersOnly bodies
+// are passed through as they stream instead of being read for printing.
+type traceTransport struct {
+	next http.RoundTripper
+	out io.Writer
+	headersOnly bool
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := []byte{}
+	if req.Body != nil && !t.headersOnly {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	fmt.Fprintf(t.out, "> %s %s\n", req.Method, req.URL)
+	printTraceHeaders(t.out, ">", req.Header)
+	printTraceBody(t.out, ">", body)
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(t.out, "< %v\n", err)
+		return nil, err
+	}
+
+	if t.headersOnly {
+		fmt.Fprintf(t.out, "< %s %s\n", res.Proto, res.Status)
+		printTraceHeaders(t.out, "<", res.Header)
+		printTraceBody(t.out, "<", nil)
+		return res, nil
+	}
+
+	body, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	fmt.Fprintf(t.out, "< %s %s\n", res.Proto, res.Status)
+	printTraceHeaders(t.out, "<", res.Header)
+	printTraceBody(t.out, "<", body)
+
+	return res, nil
+}
+
+func printTraceHeaders(out io.Writer, prefix string, header http.Header) {
+	keys := []string{}
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range header[k] {
+			if k == "Authorization" {
+				v = "[redacted]"
+			}
+			fmt.Fprintf(out, "%s %s: %s\n", prefix, k, v)
+		}
+	}
+}
+
+func printTraceBody(out io.Writer, prefix string, body []byte) {
+	fmt.Fprintf(out, "%s\n", prefix)
+	if len(body) > 0 {
+		fmt.Fprintf(out, "%s\n", bytes.TrimRight(body, "\n"))
+	}
+}
+
+// tlsVersions maps the versions tls_min_version and --strict-tls-min-version
+// accept to their crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func parseTlsVersion(name string, v string) uint16 {
+	version, ok := tlsVersions[v]
+	if !ok {
+		output.error("Invalid %s, expected 1.2 or 1.3: %s\n", name, v)
+		os.Exit(1)
+	}
+	return version
+}
+
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return name
+		}
+	}
+	return "1.2"
+}
+
+// tlsTransport refuses TLS versions older than tls_min_version, 1.2 by
+// default.
+func (c *appConfig) tlsTransport() *http.Transport {
+	minVersion := c.tlsMinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: minVersion}
+	return transport
+}
+
+// httpTransport is the transport every api request goes through.
+func (c *appConfig) httpTransport() http.RoundTripper {
+	if c.trace {
+		return &traceTransport{next: c.tlsTransport(), out: output.raw()}
+	}
+	return c.tlsTransport()
+}
+
+// downloadTransport is the transport template archives and release assets
+// are downloaded through. Their bodies are too large to hold for --trace,
+// which shows just the headers.
+func (c *appConfig) downloadTransport() http.RoundTripper {
+	if c.trace {
+		return &traceTransport{next: c.tlsTransport(), out: output.raw(), headersOnly: true}
+	}
+	return c.tlsTransport()
+}
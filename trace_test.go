@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceTransport(t *testing.T) {
+	api := newMockApi(t)
+	api.handle("POST /echo", http.StatusCreated, `{"id": 1}`)
+
+	out := &bytes.Buffer{}
+	client := http.Client{Transport: &traceTransport{next: http.DefaultTransport, out: out}}
+	req, _ := http.NewRequest(http.MethodPost, api.URL + "/echo", strings.NewReader(`{"name": "traced"}`))
+	req.Header.Set("Authorization", "token " + testToken)
+	req.Header.Set("User-Agent", "Go")
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	trace := out.String()
+	for _, want := range []string{
+		"> POST " + api.URL + "/echo\n",
+		"> Authorization: [redacted]\n",
+		"> User-Agent: Go\n",
+		"{\"name\": \"traced\"}\n",
+		"< HTTP/1.1 201 Created\n",
+		"< Content-Length: 9\n",
+		"{\"id\": 1}\n",
+	} {
+		if !strings.Contains(trace, want) {
+			t.Errorf("trace has no %q:\n%s", want, trace)
+		}
+	}
+	if strings.Contains(trace, testToken) {
+		t.Errorf("token leaked into the trace:\n%s", trace)
+	}
+	if got := api.received("POST", "/echo"); len(got) != 1 || string(got[0].Body) != `{"name": "traced"}` {
+		t.Errorf("request body not passed on: %v", got)
+	}
+}
+
+func TestTraceRun(t *testing.T) {
+	newTestEnv(t)
+	out := mustRun(t, "", "", "--trace", "traced")
+
+	if !strings.Contains(out.stderr, "> POST ") || !strings.Contains(out.stderr, "/user/repos\n") {
+		t.Errorf("create request not traced:\n%s", out.stderr)
+	}
+	if strings.Contains(out.stderr, testToken) {
+		t.Errorf("token leaked into the trace")
+	}
+}
+
+func TestTlsMinVersion(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		configLine string
+		args []string
+		want string
+	}{
+		{"", nil, "1.2"},
+		{"tls_min_version = 1.3", nil, "1.3"},
+		{"tls_min_version = 1.3", []string{"--strict-tls-min-version", "1.2"}, "1.2"},
+		{"", []string{"--strict-tls-min-version", "1.3"}, "1.3"},
+	}
+
+	for _, tt := range tests {
+		env.writeConfig(t,
+			"gh_username = " + testUser,
+			"gh_apikey = " + testToken,
+			"projects_dir = " + env.projDir,
+			"api_url = " + env.api.URL,
+			tt.configLine,
+		)
+		out := mustRun(t, "", "", append(tt.args, "--dry-run", "--print-plan", "json", "planned")...)
+		plan := map[string]any{}
+		if err := json.Unmarshal([]byte(out.stdout), &plan); err != nil {
+			t.Fatalf("invalid plan: %v\n%s", err, out.stdout)
+		}
+		if got := plan["options"].(map[string]any)["tls_min_version"]; got != tt.want {
+			t.Errorf("%q %q: tls_min_version %v, want %s", tt.configLine, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestTlsTransportMinVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	for _, tt := range []struct {
+		version uint16
+		ok bool
+	}{
+		{0, true},
+		{tls.VersionTLS12, true},
+		{tls.VersionTLS13, false},
+	} {
+		transport := (&appConfig{tlsMinVersion: tt.version}).tlsTransport()
+		want := tt.version
+		if want == 0 {
+			want = tls.VersionTLS12
+		}
+		if transport.TLSClientConfig.MinVersion != want {
+			t.Errorf("MinVersion %x, want %x", transport.TLSClientConfig.MinVersion, want)
+		}
+
+		transport.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+		res, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err == nil {
+			res.Body.Close()
+		}
+		if (err == nil) != tt.ok {
+			t.Errorf("min version %x against a TLS 1.2 server: %v", tt.version, err)
+		}
+	}
+}
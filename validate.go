@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}\s]+)\}\}`)
+
+// validateTemplate checks the template directory dir and prints each
+// problem found: a manifest that does not parse, an extended template that
+// cannot be found, symlinks pointing outside of it and placeholders no
+// variable fills. It exits with 1 if there were any.
+func validateTemplate(dir string) {
+	problems := []string{}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		output.error("Template directory not found: %s\n", dir)
+		os.Exit(1)
+	}
+
+	vars := []string{}
+	for k := range templateVars("project", "") {
+		vars = append(vars, k)
+	}
+
+	// Extended templates are fetched only for their manifest and removed
+	// right after reading it, so none is left behind on exit.
+	seen := []string{}
+	description := ""
+	for ref := dir; ref != ""; {
+		refDir := ref
+		if _, err := os.Stat(ref); err != nil {
+			output.step("Fetching extended template %s...\n", ref)
+			refDir, err = readTemplate(ref, &appConfig{retry: newRetryBudget()})
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", ref, err))
+				break
+			}
+		}
+		seen = append(seen, ref)
+
+		manifest, err := readTemplateManifest(refDir)
+		if refDir != ref {
+			os.RemoveAll(refDir)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", ref, err))
+			break
+		}
+		for _, v := range manifest.Variables {
+			vars = append(vars, v.Name)
+		}
+		if description == "" {
+			description = manifest.Description
+		}
+
+		if slices.Contains(seen, manifest.Extends) {
+			problems = append(problems, fmt.Sprintf("%s: template extends itself", ref))
+			break
+		}
+		ref = manifest.Extends
+	}
+
+	for _, m := range placeholderPattern.FindAllStringSubmatch(description, -1) {
+		if m[1] != "lang" && !slices.Contains(vars, m[1]) {
+			problems = append(problems, fmt.Sprintf("%s: no variable for description placeholder %s", templateManifestName, m[0]))
+		}
+	}
+
+	ignore, err := readTemplateIgnore(dir)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %v", templateIgnoreName, err))
+	}
+
+	root, err := filepath.EvalSymlinks(dir)
+	iferr("Failed to resolve template dir: %v\n", err)
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." && ignore.ignores(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == templateManifestName || rel == templateIgnoreName {
+			return nil
+		}
+		if d.Type() & fs.ModeSymlink != 0 {
+			if !linkInside(p, root) {
+				problems = append(problems, fmt.Sprintf("%s: symlink points outside of the template", rel))
+			}
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(data, []byte{0}) {
+			return nil
+		}
+
+		for n, line := range bytes.Split(data, []byte("\n")) {
+			for _, m := range placeholderPattern.FindAllSubmatch(line, -1) {
+				if !slices.Contains(vars, string(m[1])) {
+					problems = append(problems, fmt.Sprintf("%s:%d: no variable for placeholder %s", rel, n + 1, m[0]))
+				}
+			}
+		}
+		return nil
+	})
+	iferr("Failed to read template files: %v\n", err)
+
+	if len(problems) > 0 {
+		for _, p := range problems {
+			output.error("%s\n", p)
+		}
+		os.Exit(1)
+	}
+
+	output.step("Template %s is valid\n", dir)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// emptyTmpDir points TMPDIR at a fresh dir and returns it, for checking
+// that nothing is left behind there.
+func emptyTmpDir(t *testing.T) string {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	return tmp
+}
+
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Errorf("left behind in %s: %s", dir, e.Name())
+	}
+}
+
+func TestValidateTemplateMissingExtends(t *testing.T) {
+	newTestEnv(t)
+	dir := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "gone")
+	writeFile(t, filepath.Join(dir, templateManifestName), `{"extends": "` + missing + `"}`)
+	writeFile(t, filepath.Join(dir, "README.md"), "# {{name}} on {{port}}\n")
+	tmp := emptyTmpDir(t)
+
+	out := runMain(t, "", "", "--validate-template", dir)
+
+	if out.code != 1 {
+		t.Errorf("exit %d, want 1", out.code)
+	}
+	if !strings.Contains(out.stderr, missing + ": Failed to clone template repository " + missing) {
+		t.Errorf("missing extended template not reported:\n%s", out.stderr)
+	}
+	if !strings.Contains(out.stderr, "README.md:1: no variable for placeholder {{port}}") {
+		t.Errorf("problems after the missing template not reported:\n%s", out.stderr)
+	}
+	assertEmptyDir(t, tmp)
+}
+
+func TestValidateTemplateExtends(t *testing.T) {
+	newTestEnv(t)
+	base := initTemplateRepo(t, map[string]string{
+		templateManifestName: `{"variables": [{"name": "port"}]}`,
+	})
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, templateManifestName), `{"extends": "` + base + `"}`)
+	writeFile(t, filepath.Join(dir, "README.md"), "# {{name}} on {{port}}\n")
+	tmp := emptyTmpDir(t)
+
+	out := runMain(t, "", "", "--validate-template", dir)
+	if out.code != 0 || !strings.Contains(out.stdout, "Template " + dir + " is valid") {
+		t.Errorf("exit %d, output:\n%s%s", out.code, out.stdout, out.stderr)
+	}
+	assertEmptyDir(t, tmp)
+}
+
+func TestTemplateChainCleanup(t *testing.T) {
+	env := newTestEnv(t)
+	missing := filepath.Join(t.TempDir(), "gone")
+	child := initTemplateRepo(t, map[string]string{
+		templateManifestName: `{"extends": "` + missing + `"}`,
+		"README.md": "# {{name}}\n",
+	})
+	tmp := emptyTmpDir(t)
+
+	out := runMain(t, "", "", "--template-git", child, "orphan")
+	if out.code != 1 || !strings.Contains(out.stderr, "Failed to clone template repository " + missing) {
+		t.Errorf("exit %d, stderr %q", out.code, out.stderr)
+	}
+	if got := len(env.api.received("POST", ".*")); got != 0 {
+		t.Errorf("repository created despite the missing base template")
+	}
+	assertEmptyDir(t, tmp)
+}
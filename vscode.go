@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const vscodeSettings = `{
+	"files.insertFinalNewline": true,
+	"files.trimTrailingWhitespace": true
+}
+`
+
+// vscodeTemplates holds settings.json and extensions.json for each language
+// template, projects without one get vscodeSettings alone.
+var vscodeTemplates = map[string][2]string{
+	"go": {
+		`{
+	"files.insertFinalNewline": true,
+	"files.trimTrailingWhitespace": true,
+	"go.useLanguageServer": true,
+	"gopls": {
+		"ui.semanticTokens": true,
+		"formatting.gofumpt": false,
+		"ui.diagnostic.staticcheck": true
+	},
+	"[go]": {
+		"editor.formatOnSave": true,
+		"editor.codeActionsOnSave": {
+			"source.organizeImports": "explicit"
+		}
+	}
+}
+`,
+		`{
+	"recommendations": ["golang.go"]
+}
+`,
+	},
+}
+
+func createVscodeSettings(projPath string, templates []string) {
+	dir := filepath.Join(projPath, ".vscode")
+	err := os.MkdirAll(dir, 0755)
+	iferr("Failed to create .vscode dir: %v\n", err)
+
+	vscode, ok := vscodeTemplates[primaryLanguage(templates)]
+	if !ok {
+		f := createFile(filepath.Join(dir, "settings.json"))
+		f.WriteString(vscodeSettings)
+		f.Close()
+		return
+	}
+
+	f := createFile(filepath.Join(dir, "settings.json"))
+	f.WriteString(vscode[0])
+	f.Close()
+
+	f = createFile(filepath.Join(dir, "extensions.json"))
+	f.WriteString(vscode[1])
+	f.Close()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readJsonFile(t *testing.T, p string) map[string]any {
+	t.Helper()
+	v := map[string]any{}
+	if err := json.Unmarshal([]byte(readFile(t, p)), &v); err != nil {
+		t.Fatalf("%s is not json: %v", p, err)
+	}
+	return v
+}
+
+func TestVscodeSettingsGo(t *testing.T) {
+	projPath := t.TempDir()
+	createVscodeSettings(projPath, []string{"go", "docker"})
+
+	settings := readJsonFile(t, filepath.Join(projPath, ".vscode", "settings.json"))
+	gopls, ok := settings["gopls"].(map[string]any)
+	if !ok || gopls["ui.diagnostic.staticcheck"] != true || settings["go.useLanguageServer"] != true {
+		t.Errorf("settings.json has no gopls settings: %v", settings)
+	}
+
+	extensions := readJsonFile(t, filepath.Join(projPath, ".vscode", "extensions.json"))
+	if recs, _ := extensions["recommendations"].([]any); len(recs) != 1 || recs[0] != "golang.go" {
+		t.Errorf("extensions.json = %v", extensions)
+	}
+}
+
+func TestVscodeSettingsWithoutLanguage(t *testing.T) {
+	projPath := t.TempDir()
+	createVscodeSettings(projPath, nil)
+
+	settings := readJsonFile(t, filepath.Join(projPath, ".vscode", "settings.json"))
+	if settings["files.insertFinalNewline"] != true || settings["gopls"] != nil {
+		t.Errorf("settings.json = %v", settings)
+	}
+	if _, err := os.Stat(filepath.Join(projPath, ".vscode", "extensions.json")); err == nil {
+		t.Errorf("extensions.json written without a language template")
+	}
+}
+
+func TestVscodeCommitted(t *testing.T) {
+	env := newTestEnv(t)
+	mustRun(t, "", "", "--template", "go", "--owner", testUser, "--vscode", "edited")
+
+	if got := git(t, env.projPath("edited"), "ls-files", ".vscode"); got != ".vscode/extensions.json\n.vscode/settings.json" {
+		t.Errorf(".vscode files committed: %q", got)
+	}
+}